@@ -0,0 +1,321 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SetBatchFallback on a failed SQLInsertBatch, insert rows one by one
+// to find failing rows, reported as *BatchError of *RowError,
+// rows failed to validate are reported the same way, other rows are inserted
+func (fds *_FieldsMap) SetBatchFallback(on bool) {
+
+	fds.batchFallback = on
+}
+
+// SetBatchOrder rows of SQLInsertBatch are inserted in order of less
+// (stable, objptrs order if nil), e.g. parent rows before rows
+// referencing them for foreign keys checked at each row
+// example: fds.SetBatchOrder(func(a, b interface{}) bool { return a.(*Node).Depth < b.(*Node).Depth })
+func (fds *_FieldsMap) SetBatchOrder(less func(a, b interface{}) bool) {
+
+	fds.batchLess = less
+}
+
+// SQLInsertBatch insert objects with one multi-row INSERT
+// INSERT INTO `t` (...) VALUES (?, ?), (?, ?), ...
+// objptrs must point to the same struct type as fds,
+// see SetBatchOrder for order of rows, SetBatchFallback for errors of each row
+func (fds *_FieldsMap) SQLInsertBatch(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, objptrs []interface{}) error {
+
+	if err := fds.noVault("SQLInsert"); err != nil {
+		return err
+	}
+	if len(objptrs) == 0 {
+		return nil
+	}
+
+	order := make([]int, len(objptrs))
+	for i, olen := 0, len(objptrs); i < olen; i++ {
+		order[i] = i
+	}
+	if fds.batchLess != nil {
+		sort.SliceStable(order, func(i, j int) bool {
+			return fds.batchLess(objptrs[order[i]], objptrs[order[j]])
+		})
+	}
+
+	var rowErrs []*RowError
+	var rowMaps []*_FieldsMap
+	var indexes []int
+	var values []interface{}
+	for n, olen := 0, len(order); n < olen; n++ {
+		i := order[n]
+		fieldsMap, err := fds.sameTypeRowMap(objptrs[i])
+		if err == nil {
+			fieldsMap.fillStamps(true)
+			err = fieldsMap.checkValues()
+		}
+		if err != nil {
+			if !fds.batchFallback {
+				return err
+			}
+			rowErrs = append(rowErrs, &RowError{Index: i, Obj: objptrs[i], Err: err})
+			continue
+		}
+		rowMaps = append(rowMaps, fieldsMap)
+		indexes = append(indexes, i)
+		values = append(values, fieldsMap.insertValues()...)
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return err
+	}
+
+	if len(rowMaps) > 0 {
+		_, err = fds.execSQL(ctx, exec, fds.insertBatchSQL(len(rowMaps)), values...)
+		if err != nil {
+			if !fds.batchFallback {
+				return err
+			}
+			rowErrs = append(rowErrs, fds.insertEach(ctx, exec, rowMaps, indexes)...)
+		}
+	}
+
+	if len(rowErrs) > 0 {
+		sort.Slice(rowErrs, func(i, j int) bool { return rowErrs[i].Index < rowErrs[j].Index })
+		return &BatchError{Rows: rowErrs}
+	}
+
+	return nil
+}
+
+// insertEach insert rows one by one, error of each failed row
+func (fds *_FieldsMap) insertEach(ctx context.Context, exec Executor,
+	rowMaps []*_FieldsMap, indexes []int) []*RowError {
+
+	var rowErrs []*RowError
+	for i, rlen := 0, len(rowMaps); i < rlen; i++ {
+		_, err := fds.execSQL(ctx, exec, fds.insertSQL(), rowMaps[i].insertValues()...)
+		if err != nil {
+			rowErrs = append(rowErrs, &RowError{Index: indexes[i], Obj: rowMaps[i].objptr, Err: err})
+		}
+	}
+
+	return rowErrs
+}
+
+// SQLSyncByParentKey replace rows of a parent with objptrs in one tx:
+// DELETE rows where parentField = parentValue (IS NULL for nil),
+// trashed (soft deleted) rows too so their keys can be inserted again,
+// then SQLInsertBatch objptrs,
+// a new tx is used when tx is nil, empty objptrs only delete
+// example: fds.SQLSyncByParentKey(ctx, nil, db, "field_one", "parent001", rows)
+func (fds *_FieldsMap) SQLSyncByParentKey(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, parentField string, parentValue interface{}, objptrs []interface{}) error {
+
+	if fds.fieldIndex(parentField) < 0 {
+		return errors.New("no field match `sql` tag:" + parentField)
+	}
+
+	return withTx(ctx, tx, db, func(tx *sql.Tx) error {
+
+		cond, condArgs := fds.eqCond(parentField, parentValue)
+		extStr, args := fds.scopedBy(" where "+cond+" ", false, condArgs...)
+		_, err := fds.execSQL(ctx, tx, fds.deleteSQL(extStr), args...)
+		if err != nil {
+			return err
+		}
+
+		return fds.SQLInsertBatch(ctx, tx, nil, objptrs)
+	})
+}
+
+// insertBatchSQL generate sqlstr for INSERT of n rows
+func (fds *_FieldsMap) insertBatchSQL(n int) string {
+
+	vs := ", (" + placeholders(len(fds.insertValues())) + ")"
+	sqlstr := fds.insertSQL()
+
+	var b strings.Builder
+	b.Grow(len(sqlstr) + (n-1)*len(vs))
+	b.WriteString(sqlstr)
+	for i := 1; i < n; i++ {
+		b.WriteString(vs)
+	}
+
+	return b.String()
+}
+
+////////////////////////////////////////////////////////////////
+
+// BatchWriterOptions options of BatchWriter
+type BatchWriterOptions struct {
+	// Size flush when Size objects are buffered, 100 if 0
+	Size int
+	// Interval flush buffered objects at least every Interval, 1s if 0
+	Interval time.Duration
+	// OnError called with the error and objects of a failed flush
+	OnError func(err error, objptrs []interface{})
+}
+
+// BatchWriter buffer objects and insert them by SQLInsertBatch
+// on its own goroutine, without tx (autocommit)
+// example:
+// w, err := NewBatchWriter(ctx, db, fm, BatchWriterOptions{Size: 500})
+// w.Add(&row)
+// ...
+// err = w.Close() // flush remaining objects
+//
+type BatchWriter struct {
+	ctx  context.Context
+	db   *sql.DB
+	fds  *_FieldsMap
+	opts BatchWriterOptions
+
+	mu     sync.Mutex
+	buf    []interface{}
+	closed bool
+
+	flushCh chan struct{}
+	closeCh chan struct{}
+	done    chan struct{}
+}
+
+// NewBatchWriter new BatchWriter for objects of the struct type mapped by fm,
+// the goroutine stops when ctx is done or Close is called
+func NewBatchWriter(ctx context.Context, db *sql.DB, fm FieldsMap,
+	opts BatchWriterOptions) (*BatchWriter, error) {
+
+	fds, ok := fm.(*_FieldsMap)
+	if !ok {
+		return nil, errors.New("FieldsMap not created by NewFieldsMap")
+	}
+
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	if opts.Size <= 0 {
+		opts.Size = 100
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+
+	w := &BatchWriter{
+		ctx:     ctx,
+		db:      db,
+		fds:     fds,
+		opts:    opts,
+		flushCh: make(chan struct{}, 1),
+		closeCh: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+
+	return w, nil
+}
+
+// Add buffer an object, flush is triggered when Size is reached
+func (w *BatchWriter) Add(objptr interface{}) error {
+
+	if _, err := w.fds.sameTypeRowMap(objptr); err != nil {
+		return err
+	}
+
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errors.New("BatchWriter closed")
+	}
+	w.buf = append(w.buf, objptr)
+	full := len(w.buf) >= w.opts.Size
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.flushCh <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Close stop the goroutine and flush remaining objects
+func (w *BatchWriter) Close() error {
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.closeCh)
+	<-w.done
+
+	return w.flush()
+}
+
+func (w *BatchWriter) run() {
+
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.flush()
+		case <-w.flushCh:
+			w.flush()
+		case <-w.closeCh:
+			return
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// flush insert buffered objects, Size at a time
+func (w *BatchWriter) flush() error {
+
+	w.mu.Lock()
+	objs := w.buf
+	w.buf = nil
+	w.mu.Unlock()
+
+	var lastErr error
+	for len(objs) > 0 {
+		n := w.opts.Size
+		if n > len(objs) {
+			n = len(objs)
+		}
+
+		err := w.fds.SQLInsertBatch(w.ctx, nil, w.db, objs[:n])
+		if err != nil {
+			lastErr = err
+			if w.opts.OnError != nil {
+				w.opts.OnError(err, objs[:n])
+			}
+		}
+		objs = objs[n:]
+	}
+
+	return lastErr
+}
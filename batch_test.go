@@ -0,0 +1,362 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSQLInsertBatch(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	rows := []DemoRow{{FieldKey: "key001"}, {FieldKey: "key002", FieldThr: 2}}
+	err := fm.SQLInsertBatch(context.Background(), nil, db,
+		[]interface{}{&rows[0], &rows[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := fdb.LastQuery()
+	if !strings.HasSuffix(q.sql, "VALUES (?, ?, ?, ?, ?), (?, ?, ?, ?, ?)") {
+		t.Errorf("unexpected sql %q", q.sql)
+	}
+	if len(q.args) != 10 || q.args[5] != "key002" || q.args[8] != int64(2) {
+		t.Errorf("unexpected args %v", q.args)
+	}
+}
+
+func TestSQLInsertBatchEmptyAndMixed(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	if err := fm.SQLInsertBatch(ctx, nil, db, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(fdb.Queries()) != 0 {
+		t.Error("empty batch should not execute")
+	}
+
+	err := fm.SQLInsertBatch(ctx, nil, db, []interface{}{&DemoRow{}, &enumRow{}})
+	if err == nil || len(fdb.Queries()) != 0 {
+		t.Errorf("want error before exec for mixed types, got %v", err)
+	}
+}
+
+func TestSQLInsertBatchFallback(t *testing.T) {
+
+	dupErr := errors.New("duplicate entry")
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		for _, arg := range args {
+			if arg == "dup" {
+				return &fakeResult{err: dupErr}
+			}
+		}
+		return nil
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	rows := []DemoRow{{FieldKey: "key001"}, {FieldKey: "dup"}, {FieldKey: "key003"}}
+	objs := []interface{}{&rows[0], &rows[1], &enumRow{}, &rows[2]}
+
+	err := fm.SQLInsertBatch(ctx, nil, db, objs)
+	var berr *BatchError
+	if err == nil || errors.As(err, &berr) {
+		t.Fatalf("without fallback want plain error, got %v", err)
+	}
+
+	fm.SetBatchFallback(true)
+	n := len(fdb.Queries())
+	err = fm.SQLInsertBatch(ctx, nil, db, objs)
+	if !errors.As(err, &berr) || !errors.Is(err, dupErr) {
+		t.Fatalf("want *BatchError wrapping duplicate, got %v", err)
+	}
+	if len(berr.Rows) != 2 || berr.Rows[0].Index != 1 || berr.Rows[0].Obj != &rows[1] ||
+		berr.Rows[1].Index != 2 {
+		t.Errorf("unexpected rows %+v %+v", berr.Rows[0], berr.Rows[1])
+	}
+
+	// batch of 3 valid rows, then one INSERT for each
+	if got := len(fdb.Queries()) - n; got != 4 {
+		t.Errorf("got %d statements, want 4", got)
+	}
+}
+
+func TestSQLInsertBatchOrder(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+	fm.SetBatchOrder(func(a, b interface{}) bool {
+		return a.(*DemoRow).FieldThr < b.(*DemoRow).FieldThr
+	})
+
+	rows := []DemoRow{{FieldKey: "child", FieldThr: 1}, {FieldKey: "parent"},
+		{FieldKey: "child2", FieldThr: 1}}
+	err := fm.SQLInsertBatch(context.Background(), nil, db,
+		[]interface{}{&rows[0], &rows[1], &rows[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := fdb.LastQuery()
+	if q.args[0] != "parent" || q.args[5] != "child" || q.args[10] != "child2" {
+		t.Errorf("unexpected order %v", q.args)
+	}
+}
+
+func benchmarkRows(n int) []DemoRow {
+
+	rows := make([]DemoRow, n)
+	for i := range rows {
+		rows[i] = DemoRow{FieldKey: "key" + strings.Repeat("x", i%8), FieldThr: int64(i)}
+	}
+
+	return rows
+}
+
+func BenchmarkSQLInsertBatch(b *testing.B) {
+
+	db, _ := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+	rows := benchmarkRows(500)
+	objs := make([]interface{}, len(rows))
+	for i := range rows {
+		objs[i] = &rows[i]
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := fm.SQLInsertBatch(ctx, nil, db, objs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSQLInsertLoop(b *testing.B) {
+
+	db, _ := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	rows := benchmarkRows(500)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := Insert(ctx, nil, db, rows...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSQLSyncByParentKey(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "INSERT") {
+			return &fakeResult{err: errors.New("duplicate entry")}
+		}
+		return nil
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	rows := []DemoRow{{FieldKey: "key001", FieldOne: "p1"}, {FieldKey: "key002", FieldOne: "p1"}}
+	err := fm.SQLSyncByParentKey(ctx, nil, db, "field_one", "p1",
+		[]interface{}{&rows[0], &rows[1]})
+	if err == nil {
+		t.Fatal("want insert error")
+	}
+
+	qs := fdb.Queries()
+	if len(qs) != 4 || qs[0].sql != "BEGIN" || qs[3].sql != "ROLLBACK" {
+		t.Fatalf("want delete & insert rolled back, got %v", qs)
+	}
+	if qs[1].sql != "DELETE FROM `test_table`  where `field_one` = ? " || qs[1].args[0] != "p1" {
+		t.Errorf("unexpected delete %q %v", qs[1].sql, qs[1].args)
+	}
+
+	fdb.handler = nil
+	err = fm.SQLSyncByParentKey(ctx, nil, db, "field_one", "p1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.sql != "COMMIT" {
+		t.Errorf("want COMMIT, got %q", q.sql)
+	}
+
+	err = fm.SQLSyncByParentKey(ctx, nil, db, "field_one", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qs = fdb.Queries()
+	if q := qs[len(qs)-2]; q.sql != "DELETE FROM `test_table`  where `field_one` IS NULL " || len(q.args) != 0 {
+		t.Errorf("nil parent should be IS NULL, got %q %v", q.sql, q.args)
+	}
+
+	if err := fm.SQLSyncByParentKey(ctx, nil, db, "parent_id", 1, nil); err == nil {
+		t.Error("want error for unknown parent field")
+	}
+}
+
+func TestBatchWriter(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	w, err := NewBatchWriter(context.Background(), db, fm,
+		BatchWriterOptions{Size: 2, Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := make([]DemoRow, 5)
+	for i := range rows {
+		if err := w.Add(&rows[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	total := 0
+	for _, q := range fdb.Queries() {
+		total += len(q.args) / 5
+	}
+	if total != 5 {
+		t.Errorf("inserted %d rows, want 5", total)
+	}
+
+	if err := w.Add(&rows[0]); err == nil {
+		t.Error("want error adding to closed writer")
+	}
+}
+
+func TestBatchWriterInterval(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	w, _ := NewBatchWriter(context.Background(), db, fm,
+		BatchWriterOptions{Size: 100, Interval: 10 * time.Millisecond})
+	defer w.Close()
+
+	w.Add(&DemoRow{FieldKey: "key001"})
+	deadline := time.Now().Add(2 * time.Second)
+	for len(fdb.Queries()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(fdb.Queries()) == 0 {
+		t.Error("interval flush did not happen")
+	}
+}
+
+func TestBatchWriterOnError(t *testing.T) {
+
+	insertErr := errors.New("duplicate key")
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{err: insertErr}
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	var mu sync.Mutex
+	var failed int
+	w, _ := NewBatchWriter(context.Background(), db, fm, BatchWriterOptions{
+		Size:     10,
+		Interval: time.Hour,
+		OnError: func(err error, objptrs []interface{}) {
+			mu.Lock()
+			failed += len(objptrs)
+			mu.Unlock()
+		},
+	})
+
+	w.Add(&DemoRow{FieldKey: "key001"})
+	w.Add(&DemoRow{FieldKey: "key002"})
+	if err := w.Close(); err != insertErr {
+		t.Errorf("got %v, want insert error", err)
+	}
+	if failed != 2 {
+		t.Errorf("OnError got %d objects, want 2", failed)
+	}
+}
+
+func TestSQLSyncByParentKeyTrashed(t *testing.T) {
+
+	type childRow struct {
+		ID        int64      `sql:"id"`
+		ParentID  int64      `sql:"parent_id"`
+		DeletedAt *time.Time `sql:"deleted_at,softdelete"`
+	}
+
+	// child 1 of parent 7 is trashed, a DELETE without IS NULL removes it
+	rows := map[int64]bool{1: true}
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		switch {
+		case strings.HasPrefix(q, "DELETE"):
+			for id, trashed := range rows {
+				if !trashed || !strings.Contains(q, "IS NULL") {
+					delete(rows, id)
+				}
+			}
+		case strings.HasPrefix(q, "INSERT"):
+			if _, ok := rows[args[0].(int64)]; ok {
+				return &fakeResult{err: errors.New("duplicate entry")}
+			}
+			rows[args[0].(int64)] = false
+		}
+		return nil
+	})
+	defer db.Close()
+
+	var row childRow
+	fm, _ := NewFieldsMap("children", &row)
+	err := fm.SQLSyncByParentKey(context.Background(), nil, db, "parent_id", int64(7),
+		[]interface{}{&childRow{ID: 1, ParentID: 7}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	qs := fdb.Queries()
+	if q := qs[1]; q.sql != "DELETE FROM `children`  where `parent_id` = ? " {
+		t.Errorf("trashed children should be deleted too, got %q", q.sql)
+	}
+}
@@ -0,0 +1,68 @@
+package sqlmapper
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// scanRow scan current row into addrs, if it fails retry integer
+// Save slots (int64, uint64, enum) via sql.NullString & parse,
+// for drivers returning BIGINT (UNSIGNED) as padded []byte or their own type
+func scanRow(rs *sql.Rows, addrs ...interface{}) error {
+
+	err := rs.Scan(addrs...)
+	if err == nil {
+		return nil
+	}
+
+	retry := make([]interface{}, len(addrs))
+	strs := make([]*sql.NullString, len(addrs))
+	found := false
+	for i, alen := 0, len(addrs); i < alen; i++ {
+		retry[i] = addrs[i]
+		switch addrs[i].(type) {
+		case *sql.NullInt64, *sql.Null[uint64]:
+			strs[i] = &sql.NullString{}
+			retry[i] = strs[i]
+			found = true
+			break
+		default:
+		}
+	}
+	if !found || rs.Scan(retry...) != nil {
+		return err
+	}
+
+	for i, alen := 0, len(addrs); i < alen; i++ {
+		if strs[i] == nil {
+			continue
+		}
+
+		switch save := addrs[i].(type) {
+		case *sql.NullInt64:
+			*save = sql.NullInt64{}
+			if strs[i].Valid {
+				n, perr := strconv.ParseInt(strings.TrimSpace(strs[i].String), 10, 64)
+				if perr != nil {
+					return err
+				}
+				*save = sql.NullInt64{Int64: n, Valid: true}
+			}
+			break
+		case *sql.Null[uint64]:
+			*save = sql.Null[uint64]{}
+			if strs[i].Valid {
+				n, perr := strconv.ParseUint(strings.TrimSpace(strs[i].String), 10, 64)
+				if perr != nil {
+					return err
+				}
+				*save = sql.Null[uint64]{V: n, Valid: true}
+			}
+			break
+		default:
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"math"
+	"testing"
+)
+
+type counterRow struct {
+	ID    int64  `sql:"id"`
+	Hits  uint64 `sql:"hits"`
+	Total int64  `sql:"total"`
+}
+
+func TestScanBigIntBytes(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "hits", "total"},
+			rows: [][]driver.Value{{[]byte("1"), []byte("18446744073709551615"), []byte(" 42")}},
+		}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := counterRow{ID: 1}
+	fm, err := NewFieldsMap("counters", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if row.Hits != math.MaxUint64 || row.Total != 42 {
+		t.Errorf("unexpected %+v", row)
+	}
+
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.args[1] != "18446744073709551615" {
+		t.Errorf("uint64 with high bit should bind as string, got %#v", q.args[1])
+	}
+
+	row.Hits = 7
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.args[1] != int64(7) {
+		t.Errorf("uint64 bind %#v", q.args[1])
+	}
+
+	fdb.handler = func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "hits", "total"},
+			rows: [][]driver.Value{{int64(1), int64(1), []byte("18446744073709551615")}},
+		}
+	}
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err == nil {
+		t.Error("want error for int64 overflow")
+	}
+}
+
+type sizedRow struct {
+	ID    int32    `sql:"id"`
+	Level uint8    `sql:"level"`
+	Score float32  `sql:"score"`
+	Rank  *int16   `sql:"rank"`
+	Count uint     `sql:"count"`
+	Ratio *float32 `sql:"ratio"`
+}
+
+func TestNarrowNumberTypes(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "level", "score", "rank", "count", "ratio"},
+			rows: [][]driver.Value{{int64(3), int64(200), float64(1.5), int64(-7), int64(9), nil}},
+		}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := sizedRow{ID: 3}
+	fm, err := NewFieldsMap("sized", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if row.Level != 200 || row.Score != 1.5 || row.Rank == nil || *row.Rank != -7 ||
+		row.Count != 9 || row.Ratio != nil {
+		t.Errorf("unexpected %+v", row)
+	}
+
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	q := fdb.LastQuery()
+	if q.args[0] != int64(3) || q.args[1] != int64(200) || q.args[2] != float64(1.5) ||
+		q.args[3] != int64(-7) || q.args[4] != int64(9) || q.args[5] != nil {
+		t.Errorf("unexpected binds %#v", q.args)
+	}
+
+	fdb.handler = func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "level", "score", "rank", "count", "ratio"},
+			rows: [][]driver.Value{{int64(3), int64(256), float64(1), nil, int64(1), nil}},
+		}
+	}
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err == nil {
+		t.Error("want error for uint8 overflow")
+	}
+
+	fdb.handler = func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "level", "score", "rank", "count", "ratio"},
+			rows: [][]driver.Value{{int64(3), int64(-1), float64(1), nil, int64(1), nil}},
+		}
+	}
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err == nil {
+		t.Error("want error for negative uint8")
+	}
+}
@@ -0,0 +1,161 @@
+package sqlmapper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// queryCache mapped rows of SELECT by SQL + args
+type queryCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+// cacheEntry rows and tags of fields loaded
+type cacheEntry struct {
+	expires time.Time
+	objs    []interface{}
+	loaded  []string
+}
+
+// SetQueryCache cache rows of SELECT methods for ttl, keyed by SQL + args,
+// rows are copied in & out of cache so callers can not alias them.
+// statements with FOR UPDATE are never cached,
+// any write by fds drops the cache, 0 ttl disables cache
+// example: fds.SetQueryCache(time.Minute)
+func (fds *_FieldsMap) SetQueryCache(ttl time.Duration) {
+
+	if ttl <= 0 {
+		fds.cache = nil
+		return
+	}
+
+	fds.cache = &queryCache{ttl: ttl, entries: map[string]cacheEntry{}}
+}
+
+// InvalidateQueryCache drop all rows cached by fds
+func (fds *_FieldsMap) InvalidateQueryCache() {
+
+	if fds.cache == nil {
+		return
+	}
+
+	fds.cache.mu.Lock()
+	fds.cache.entries = map[string]cacheEntry{}
+	fds.cache.mu.Unlock()
+}
+
+// cacheKey key of cache, "" if sqlstr is not cacheable
+func (fds *_FieldsMap) cacheKey(mode scanMode, sqlstr string, args []interface{}) string {
+
+	if fds.cache == nil {
+		return ""
+	}
+
+	lower := strings.ToLower(sqlstr)
+	if !strings.HasPrefix(strings.TrimSpace(lower), "select") ||
+		strings.Contains(lower, "for update") {
+		return ""
+	}
+
+	return fmt.Sprintf("%d\x00%s\x00%#v", mode, sqlstr, args)
+}
+
+// cacheGet copy of rows cached by key
+func (fds *_FieldsMap) cacheGet(key string) ([]interface{}, []string, bool) {
+
+	fds.cache.mu.Lock()
+	entry, ok := fds.cache.entries[key]
+	if ok && time.Now().After(entry.expires) {
+		delete(fds.cache.entries, key)
+		ok = false
+	}
+	fds.cache.mu.Unlock()
+	if !ok {
+		return nil, nil, false
+	}
+
+	return fds.copyObjs(entry.objs), entry.loaded, true
+}
+
+// cachePut cache copy of rows by key
+func (fds *_FieldsMap) cachePut(key string, objs []interface{}, loaded []string) {
+
+	entry := cacheEntry{
+		expires: time.Now().Add(fds.cache.ttl),
+		objs:    fds.copyObjs(objs),
+		loaded:  loaded,
+	}
+
+	fds.cache.mu.Lock()
+	fds.cache.entries[key] = entry
+	fds.cache.mu.Unlock()
+}
+
+// copyObjs deep copy of each *struct in objs
+func (fds *_FieldsMap) copyObjs(objs []interface{}) []interface{} {
+
+	copies := make([]interface{}, len(objs))
+	for i, olen := 0, len(objs); i < olen; i++ {
+		v := reflect.New(fds.reftype)
+		v.Elem().Set(reflect.ValueOf(objs[i]).Elem())
+		deepCopy(v.Elem())
+		copies[i] = v.Interface()
+	}
+
+	return copies
+}
+
+// deepCopy replace pointer, slice & map targets reachable from
+// settable v by new copies, v already holds a shallow copy,
+// unexported struct fields (e.g. of time.Time) stay shallow
+func deepCopy(v reflect.Value) {
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(v.Elem())
+		deepCopy(p.Elem())
+		v.Set(p)
+	case reflect.Struct:
+		for i, flen := 0, v.NumField(); i < flen; i++ {
+			if f := v.Field(i); f.CanSet() {
+				deepCopy(f)
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(s, v)
+		for i, slen := 0, s.Len(); i < slen; i++ {
+			deepCopy(s.Index(i))
+		}
+		v.Set(s)
+	case reflect.Array:
+		for i, alen := 0, v.Len(); i < alen; i++ {
+			deepCopy(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			e := reflect.New(v.Type().Elem()).Elem()
+			e.Set(iter.Value())
+			deepCopy(e)
+			m.SetMapIndex(iter.Key(), e)
+		}
+		v.Set(m)
+	}
+}
@@ -0,0 +1,128 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestSetQueryCache(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return demoRowsResult(2)
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "keya"}
+	fm, _ := NewFieldsMap(table, &row)
+	fm.SetQueryCache(time.Minute)
+
+	objs, err := fm.SQLSelectAllRows(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objs[0].(*DemoRow).FieldOne = "mutated"
+
+	n := len(fdb.Queries())
+	cached, err := fm.SQLSelectAllRows(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fdb.Queries()) != n {
+		t.Error("second select should hit cache")
+	}
+	if len(cached) != 2 || cached[0].(*DemoRow).FieldOne != "one" || cached[0] == objs[0] {
+		t.Errorf("cached rows must be copies, got %+v", cached[0])
+	}
+
+	if _, err := fm.SQLSelectAllRows(ctx, nil, db); err != nil || len(fdb.Queries()) != n {
+		t.Error("select should still hit cache")
+	}
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil || len(fdb.Queries()) != n+1 {
+		t.Errorf("select by key should hit cache, %d queries", len(fdb.Queries())-n)
+	}
+	if _, err := fm.SQLLockByPriKey(ctx, nil, db); err != nil || len(fdb.Queries()) != n+2 {
+		t.Error("FOR UPDATE must not be cached")
+	}
+
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	n = len(fdb.Queries())
+	if _, err := fm.SQLSelectAllRows(ctx, nil, db); err != nil || len(fdb.Queries()) != n+1 {
+		t.Error("write should drop cache")
+	}
+
+	fm.InvalidateQueryCache()
+	if _, err := fm.SQLSelectAllRows(ctx, nil, db); err != nil || len(fdb.Queries()) != n+2 {
+		t.Error("invalidate should drop cache")
+	}
+}
+
+func TestQueryCacheTTL(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return demoRowsResult(1)
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+	fm.SetQueryCache(time.Millisecond)
+
+	fm.SQLSelectAllRows(ctx, nil, db)
+	time.Sleep(5 * time.Millisecond)
+	fm.SQLSelectAllRows(ctx, nil, db)
+	if len(fdb.Queries()) != 2 {
+		t.Errorf("expired rows should be selected again, %d queries", len(fdb.Queries()))
+	}
+}
+
+func TestQueryCacheDeepCopy(t *testing.T) {
+
+	seen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "name", "count", "score", "flag", "seen"},
+			rows: [][]driver.Value{{int64(1), "bob", int64(2), nil, true, seen}},
+		}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row nullableRow
+	fm, _ := NewFieldsMap("nullable_table", &row)
+	fm.SetQueryCache(time.Minute)
+
+	objs, err := fm.SQLSelectAllRows(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := objs[0].(*nullableRow)
+	*first.Name = "mutated"
+	*first.Count = 99
+	*first.Seen = time.Time{}
+
+	n := len(fdb.Queries())
+	cached, err := fm.SQLSelectAllRows(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fdb.Queries()) != n {
+		t.Error("second select should hit cache")
+	}
+	second := cached[0].(*nullableRow)
+	if *second.Name != "bob" || *second.Count != 2 || !second.Seen.Equal(seen) {
+		t.Errorf("mutation after first read leaked into cache, got %q %d %v",
+			*second.Name, *second.Count, *second.Seen)
+	}
+	if second.Name == first.Name || second.Seen == first.Seen {
+		t.Error("cached rows must not share pointer targets")
+	}
+}
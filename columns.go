@@ -0,0 +1,41 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// SQLSelectColumns select only columns cols (by `sql` tag) of rows,
+// fields of other columns are left zero, error if a column matches no field,
+// for a narrower struct of the same table NewFieldsMap of it is enough
+// example: fds.SQLSelectColumns(ctx, tx, db, []string{"field_key", "field_one"},
+// 	" where `field_thr` > ? ", 10)
+func (fds *_FieldsMap) SQLSelectColumns(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	cols []string, extStr string, args ...interface{}) ([]interface{}, error) {
+
+	if len(cols) == 0 {
+		return nil, errors.New("no column to select")
+	}
+
+	var colsStr string
+	for i, clen := 0, len(cols); i < clen; i++ {
+		idx := fds.fieldIndex(cols[i])
+		if idx < 0 {
+			return nil, errors.New("no field match `sql` tag:" + cols[i])
+		}
+		if len(colsStr) > 0 {
+			colsStr += ", "
+		}
+		colsStr += fds.selectColumn(idx)
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	extStr, args = fds.scoped(extStr, args...)
+	sqlstr := fds.verb("SELECT") + " " + colsStr + " FROM " + fds.quoteTable() + " " + extStr
+	return fds.selectRows(ctx, exec, scanByNamePartial, sqlstr, args...)
+}
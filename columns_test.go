@@ -0,0 +1,59 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestSQLSelectColumns(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"field_key", "field_thr"},
+			rows: [][]driver.Value{{"keya", int64(3)}, {"keyb", int64(4)}},
+		}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	objs, err := fm.SQLSelectColumns(ctx, nil, db, []string{"field_key", "field_thr"},
+		" where `field_thr` > ? ", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := fdb.LastQuery()
+	if q.sql != "SELECT  `field_key`, `field_thr` FROM `test_table`  where `field_thr` > ? " {
+		t.Errorf("unexpected sql %q", q.sql)
+	}
+	if len(objs) != 2 {
+		t.Fatalf("got %d rows", len(objs))
+	}
+	got := objs[1].(*DemoRow)
+	if got.FieldKey != "keyb" || got.FieldThr != 4 || got.FieldOne != "" || got.FieldFou != 0 {
+		t.Errorf("unexpected row %+v", got)
+	}
+
+	if _, err := fm.SQLSelectColumns(ctx, nil, db, []string{"field_key", "nope"}, ""); err == nil {
+		t.Error("want error for unknown column")
+	}
+	if _, err := fm.SQLSelectColumns(ctx, nil, db, nil, ""); err == nil {
+		t.Error("want error for no column")
+	}
+
+	// a narrower struct of the same table selects only its columns
+	var item struct {
+		FieldKey string `sql:"field_key"`
+		FieldThr int64  `sql:"field_thr"`
+	}
+	pfm, _ := NewFieldsMap(table, &item)
+	if _, err := pfm.SQLSelectAllRows(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.sql != "SELECT  `field_key`, `field_thr`  FROM `test_table` " {
+		t.Errorf("unexpected projection sql %q", q.sql)
+	}
+}
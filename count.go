@@ -0,0 +1,111 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// SQLCountDistinct count distinct tuples of fields by `sql` tags,
+// extStr & args for WHERE etc.
+// example: fds.SQLCountDistinct(ctx, tx, db, []string{"field_one", "field_two"},
+// 	" where `field_thr` > ? ", 10)
+// SELECT COUNT(DISTINCT `field_one`, `field_two`) FROM `test_table` where ...
+// with Postgres dialect, a row of several fields:
+// SELECT COUNT(DISTINCT ("field_one", "field_two")) FROM "test_table" where ...
+func (fds *_FieldsMap) SQLCountDistinct(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	namesInDB []string, extStr string, args ...interface{}) (int64, error) {
+
+	if len(namesInDB) == 0 {
+		return 0, errors.New("no column to count distinct")
+	}
+
+	cols := make([]string, len(namesInDB))
+	for i, nlen := 0, len(namesInDB); i < nlen; i++ {
+		idx := fds.fieldIndex(namesInDB[i])
+		if idx < 0 {
+			return 0, errors.New("no field match `sql` tag:" + namesInDB[i])
+		}
+		if len(fds.fields[idx].expr) > 0 {
+			return 0, errors.New("count distinct of select-only field:" + namesInDB[i])
+		}
+		cols[i] = fds.quote(fds.fields[idx].Tag)
+	}
+
+	tuple := strings.Join(cols, ", ")
+	if _, pg := fds.dialect.(postgresDialect); pg && len(cols) > 1 {
+		tuple = "(" + tuple + ")"
+	}
+
+	return fds.count(ctx, tx, db, "COUNT(DISTINCT "+tuple+")", extStr, args...)
+}
+
+// SQLCount count rows matching extStr & args
+// example: fds.SQLCount(ctx, tx, db, " where `field_thr` > ? ", 10)
+// SELECT COUNT(*) FROM `test_table` where `field_thr` > ?
+func (fds *_FieldsMap) SQLCount(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	extStr string, args ...interface{}) (int64, error) {
+
+	return fds.count(ctx, tx, db, "COUNT(*)", extStr, args...)
+}
+
+// SQLCountAll count all rows in table
+func (fds *_FieldsMap) SQLCountAll(ctx context.Context, tx *sql.Tx, db *sql.DB) (int64, error) {
+
+	return fds.count(ctx, tx, db, "COUNT(*)", "")
+}
+
+// SQLExistsByPriKey row of primary key (fields) of Object(struct) exists,
+// no column is scanned
+// SELECT 1 FROM `test_table` where `field_key` = ? LIMIT 1
+func (fds *_FieldsMap) SQLExistsByPriKey(ctx context.Context, tx *sql.Tx,
+	db *sql.DB) (bool, error) {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	extStr, args := fds.scoped(" where "+fds.priKeyCond()+" LIMIT 1", fds.priKeyValues()...)
+	sqlstr := fds.verb("SELECT") + "1 FROM " + fds.quoteTable() + " " + extStr
+	err = fds.queryRows(ctx, exec, sqlstr, args, func(rs *sql.Rows) error {
+		found = rs.Next()
+		return rs.Err()
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return found, nil
+}
+
+// count SELECT expr FROM table extStr, scan one int64
+func (fds *_FieldsMap) count(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	expr string, extStr string, args ...interface{}) (int64, error) {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	extStr, args = fds.scoped(extStr, args...)
+	sqlstr := fds.verb("SELECT") + expr + " FROM " + fds.quoteTable() + " " + extStr
+	err = fds.queryRows(ctx, exec, sqlstr, args, func(rs *sql.Rows) error {
+		if rs.Next() {
+			err := rs.Scan(&n)
+			if err != nil {
+				return err
+			}
+		}
+
+		return rs.Err()
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
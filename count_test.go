@@ -0,0 +1,119 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestSQLCountDistinct(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{cols: []string{"n"}, rows: [][]driver.Value{{int64(7)}}}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	n, err := fm.SQLCountDistinct(ctx, nil, db, []string{"field_one", "field_two"},
+		" where `field_thr` > ? ", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 7 {
+		t.Errorf("got %d, want 7", n)
+	}
+	q := fdb.LastQuery()
+	if q.sql != "SELECT COUNT(DISTINCT `field_one`, `field_two`) FROM `test_table`  where `field_thr` > ? " ||
+		len(q.args) != 1 {
+		t.Errorf("unexpected %q %v", q.sql, q.args)
+	}
+
+	if _, err := fm.SQLCountDistinct(ctx, nil, db, nil, ""); err == nil {
+		t.Error("want error for no column")
+	}
+	if _, err := fm.SQLCountDistinct(ctx, nil, db, []string{"field_one", "nope"}, ""); err == nil {
+		t.Error("want error for unknown field")
+	}
+
+	pg, _ := NewFieldsMapWithDialect(table, &row, Postgres)
+	if _, err := pg.SQLCountDistinct(ctx, nil, db, []string{"field_one", "field_two"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.sql != `SELECT COUNT(DISTINCT ("field_one", "field_two")) FROM "test_table" ` {
+		t.Errorf("unexpected postgres %q", q.sql)
+	}
+	if _, err := pg.SQLCountDistinct(ctx, nil, db, []string{"field_one"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.sql != `SELECT COUNT(DISTINCT "field_one") FROM "test_table" ` {
+		t.Errorf("unexpected postgres %q", q.sql)
+	}
+}
+
+func TestSQLCount(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		n := int64(10)
+		if len(args) > 0 {
+			n = 4
+		}
+		return &fakeResult{cols: []string{"n"}, rows: [][]driver.Value{{n}}}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	n, err := fm.SQLCountAll(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); n != 10 || q.sql != "SELECT COUNT(*) FROM `test_table` " {
+		t.Errorf("got %d by %q", n, q.sql)
+	}
+
+	n, err = fm.SQLCount(ctx, nil, db, " where `field_thr` > ? ", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); n != 4 || q.sql != "SELECT COUNT(*) FROM `test_table`  where `field_thr` > ? " {
+		t.Errorf("got %d by %q", n, q.sql)
+	}
+}
+
+func TestSQLExistsByPriKey(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		res := &fakeResult{cols: []string{"1"}}
+		if args[0] == "keya" {
+			res.rows = [][]driver.Value{{int64(1)}}
+		}
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "keya"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	ok, err := fm.SQLExistsByPriKey(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !ok || q.sql != "SELECT 1 FROM `test_table`  where `field_key` = ? LIMIT 1" {
+		t.Errorf("got %v by %q", ok, q.sql)
+	}
+
+	row.FieldKey = "keyb"
+	ok, err = fm.SQLExistsByPriKey(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("keyb should not exist")
+	}
+}
@@ -0,0 +1,268 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// csvImportBatch rows of one INSERT by SQLImportCSV
+const csvImportBatch = 100
+
+// SQLExportCSV stream rows selected by extStr & args to w as CSV,
+// header is `sql` tags, one row in memory at a time.
+// NULL is an empty value, time.Time is RFC3339 in UTC, enum is its number,
+// a custom type is its driver.Valuer value
+// example: fds.SQLExportCSV(ctx, nil, db, " where `field_two` = ? ", w, true)
+func (fds *_FieldsMap) SQLExportCSV(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	extStr string, w io.Writer, args ...interface{}) error {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	err = cw.Write(fds.GetFieldNamesInDB())
+	if err != nil {
+		return err
+	}
+
+	extStr, args = fds.scoped(extStr, args...)
+	record := make([]string, len(fds.fields))
+	_, err = fds.scanEach(ctx, exec, scanByPosition, fds.selectSQL(extStr), args,
+		func(fieldsMap *_FieldsMap) error {
+			for i, flen := 0, len(fieldsMap.fields); i < flen; i++ {
+				var err error
+				record[i], err = fieldsMap.csvValue(i)
+				if err != nil {
+					return fmt.Errorf("`%s`: %w", fieldsMap.fields[i].Tag, err)
+				}
+			}
+			return cw.Write(record)
+		})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvValue field idx as CSV value, empty if NULL scanned
+func (fds *_FieldsMap) csvValue(idx int) (string, error) {
+
+	if !fds.saveValid(idx) {
+		return "", nil
+	}
+
+	v := reflect.ValueOf(fds.fields[idx].Addr).Elem()
+	if fds.fields[idx].ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	switch baseType(fds.fields[idx].Type) {
+	case "int64", "enum":
+		if v.CanUint() {
+			return strconv.FormatUint(v.Uint(), 10), nil
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	case "uint64":
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case "string":
+		return v.String(), nil
+	case "float64":
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), nil
+	case "bool":
+		return strconv.FormatBool(v.Bool()), nil
+	case "time.Time":
+		return v.Interface().(time.Time).UTC().Format(time.RFC3339Nano), nil
+	case "scanner":
+		dv, err := v.Interface().(driver.Valuer).Value()
+		if err != nil {
+			return "", err
+		}
+		return csvDriverValue(dv)
+	default:
+	}
+
+	return "", errors.New("unsupported type " + fds.fields[idx].Type)
+}
+
+// csvDriverValue driver.Value as CSV value, formatted as csvValue does
+func csvDriverValue(dv driver.Value) (string, error) {
+
+	switch x := dv.(type) {
+	case nil:
+		return "", nil
+	case int64:
+		return strconv.FormatInt(x, 10), nil
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	case []byte:
+		return string(x), nil
+	case string:
+		return x, nil
+	case time.Time:
+		return x.UTC().Format(time.RFC3339Nano), nil
+	default:
+	}
+
+	return "", fmt.Errorf("unsupported driver value %T", dv)
+}
+
+// SQLImportCSV insert rows read from CSV r in one tx, INSERT of
+// csvImportBatch rows at a time, return rows inserted.
+// header are `sql` tags in any order, fields not in header are left zero,
+// values are parsed as written by SQLExportCSV, empty value is zero value,
+// a new tx is used when tx is nil, nothing is inserted on error
+func (fds *_FieldsMap) SQLImportCSV(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	r io.Reader) (int64, error) {
+
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return 0, errors.New("csv has no header")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	idxs := make([]int, len(header))
+	for i, hlen := 0, len(header); i < hlen; i++ {
+		idxs[i] = fds.fieldIndex(header[i])
+		if idxs[i] < 0 {
+			return 0, errors.New("no field match `sql` tag:" + header[i])
+		}
+	}
+
+	var total int64
+	err = withTx(ctx, tx, db, func(tx *sql.Tx) error {
+
+		var objs []interface{}
+		for {
+			record, err := cr.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+
+			obj := reflect.New(fds.reftype).Interface()
+			fieldsMap, err := fds.newRowMap(obj)
+			if err != nil {
+				return err
+			}
+			for i, rlen := 0, len(record); i < rlen; i++ {
+				err = fieldsMap.setCSVValue(idxs[i], record[i])
+				if err != nil {
+					line, _ := cr.FieldPos(i)
+					return fmt.Errorf("line %d: `%s`: %w", line, header[i], err)
+				}
+			}
+
+			objs = append(objs, obj)
+			if len(objs) == csvImportBatch {
+				err = fds.SQLInsertBatch(ctx, tx, nil, objs)
+				if err != nil {
+					return err
+				}
+				total += int64(len(objs))
+				objs = objs[:0]
+			}
+		}
+
+		err := fds.SQLInsertBatch(ctx, tx, nil, objs)
+		if err != nil {
+			return err
+		}
+		total += int64(len(objs))
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// setCSVValue parse CSV value s into field idx, empty s is zero value,
+// a custom type Scan s as string
+func (fds *_FieldsMap) setCSVValue(idx int, s string) error {
+
+	v := reflect.ValueOf(fds.fields[idx].Addr).Elem()
+	if len(s) == 0 {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if fds.fields[idx].ptr {
+		nv := reflect.New(v.Type().Elem())
+		v.Set(nv)
+		v = nv.Elem()
+	}
+
+	switch baseType(fds.fields[idx].Type) {
+	case "int64", "enum":
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		if err := setNarrowInt(v.Addr().Interface(), n); err != nil {
+			return err
+		}
+		break
+	case "uint64":
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		if v.OverflowUint(n) {
+			return fmt.Errorf("%d overflows %s", n, v.Type())
+		}
+		v.SetUint(n)
+		break
+	case "string":
+		v.SetString(s)
+		break
+	case "float64":
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		break
+	case "bool":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+		break
+	case "time.Time":
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t.UTC()))
+		break
+	case "scanner":
+		return v.Addr().Interface().(sql.Scanner).Scan(s)
+	default:
+		return errors.New("unsupported type " + fds.fields[idx].Type)
+	}
+
+	return nil
+}
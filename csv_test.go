@@ -0,0 +1,133 @@
+package sqlmapper
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSQLExportCSV(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"field_key", "field_one", "field_two", "field_thr", "field_fou"},
+			rows: [][]driver.Value{
+				{"key001", `say "hi", bye`, true, int64(3), 1.5},
+				{"key002", "multi\nline", false, nil, 0.1},
+			},
+		}
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	var buf bytes.Buffer
+	err := fm.SQLExportCSV(context.Background(), nil, db, " where `field_thr` > ? ", &buf, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "field_key,field_one,field_two,field_thr,field_fou\n" +
+		"key001,\"say \"\"hi\"\", bye\",true,3,1.5\n" +
+		"key002,\"multi\nline\",false,,0.1\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+	if q := fdb.LastQuery(); len(q.args) != 1 {
+		t.Errorf("args %v", q.args)
+	}
+}
+
+func TestSQLImportCSV(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	in := "field_thr,field_key,field_one\n" +
+		"3,key001,\"say \"\"hi\"\", bye\"\n" +
+		",key002,two\n"
+	n, err := fm.SQLImportCSV(ctx, nil, db, strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("got %d rows, want 2", n)
+	}
+
+	qs := fdb.Queries()
+	insert := qs[len(qs)-2]
+	want := []driver.Value{"key001", `say "hi", bye`, false, int64(3), 0.0,
+		"key002", "two", false, int64(0), 0.0}
+	if fmt.Sprint(insert.args) != fmt.Sprint(want) {
+		t.Errorf("got %v\nwant %v", insert.args, want)
+	}
+	if qs[len(qs)-1].sql != "COMMIT" {
+		t.Errorf("want COMMIT, got %q", qs[len(qs)-1].sql)
+	}
+
+	in = "field_key,field_thr\nkey001,1\nkey002,x\n"
+	_, err = fm.SQLImportCSV(ctx, nil, db, strings.NewReader(in))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3: `field_thr`: ") {
+		t.Errorf("want parse error on line 3, got %v", err)
+	}
+
+	_, err = fm.SQLImportCSV(ctx, nil, db, strings.NewReader("field_key,legacy\n"))
+	if err == nil {
+		t.Error("want error for unknown header")
+	}
+}
+
+func TestCSVScannerValuerRoundTrip(t *testing.T) {
+
+	rows := [][]driver.Value{
+		{int64(1), "live", `["a","b, c"]`, "hi"},
+		{int64(2), "draft", nil, nil},
+	}
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{cols: []string{"id", "status", "tags", "note"}, rows: rows}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row postRow
+	fm, _ := NewFieldsMap("posts", &row)
+
+	var buf bytes.Buffer
+	if err := fm.SQLExportCSV(ctx, nil, db, "", &buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,status,tags,note\n" +
+		"1,live,\"[\"\"a\"\",\"\"b, c\"\"]\",hi\n" +
+		"2,draft,null,\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	n, err := fm.SQLImportCSV(ctx, nil, db, strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("got %d rows, want 2", n)
+	}
+	qs := fdb.Queries()
+	got := qs[len(qs)-2].args
+	wantArgs := []driver.Value{int64(1), "live", `["a","b, c"]`, "hi",
+		int64(2), "draft", "null", nil}
+	if fmt.Sprint(got) != fmt.Sprint(wantArgs) {
+		t.Errorf("import got %v\nwant %v", got, wantArgs)
+	}
+
+	_, err = fm.SQLImportCSV(ctx, nil, db, strings.NewReader("id,status\n3,archived\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2: `status`: ") {
+		t.Errorf("want Scan error on line 2, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// columnType column type in db for Field by dialect of fds,
+// tag option `sql:"name,type=VARCHAR(64)"` overrides it
+func (fds *_FieldsMap) columnType(idx int) string {
+
+	if len(fds.fields[idx].coltype) > 0 {
+		return fds.fields[idx].coltype
+	}
+
+	_, pg := fds.dialect.(postgresDialect)
+	switch baseType(fds.fields[idx].Type) {
+	case "int64", "enum":
+		return "BIGINT"
+	case "uint64":
+		if pg {
+			return "NUMERIC(20)"
+		}
+		return "BIGINT UNSIGNED"
+	case "string":
+		return "VARCHAR(255)"
+	case "float64":
+		if pg {
+			return "DOUBLE PRECISION"
+		}
+		return "DOUBLE"
+	case "bool":
+		if pg {
+			return "BOOLEAN"
+		}
+		return "TINYINT(1)"
+	case "time.Time":
+		if len(fds.fields[idx].epoch) > 0 {
+			return "BIGINT"
+		}
+		if pg {
+			return "TIMESTAMP"
+		}
+		return "DATETIME"
+	default:
+	}
+
+	return "TEXT"
+}
+
+// columnDef column definition in DDL for Field, primary key is NOT NULL
+// and auto primary key AUTO_INCREMENT (MySQL) or IDENTITY (Postgres),
+// with COMMENT of tag option `sql:"status,comment='order status'"` on MySQL,
+// see columnComment for Postgres
+// example:"`field_one` VARCHAR(255)"
+func (fds *_FieldsMap) columnDef(idx int) string {
+
+	_, pg := fds.dialect.(postgresDialect)
+	def := fds.quote(fds.fields[idx].Tag) + " " + fds.columnType(idx)
+	if fds.isPriKey(idx) {
+		def += " NOT NULL"
+		if fds.fields[idx].auto {
+			if pg {
+				def += " GENERATED BY DEFAULT AS IDENTITY"
+			} else {
+				def += " AUTO_INCREMENT"
+			}
+		}
+	}
+	if len(fds.fields[idx].comment) > 0 && !pg {
+		def += " COMMENT " + quoteString(fds.fields[idx].comment)
+	}
+
+	return def
+}
+
+// columnComment COMMENT ON COLUMN statement of Field on Postgres,
+// empty on MySQL (see columnDef) or without comment
+// example:"COMMENT ON COLUMN \"t\".\"status\" IS 'order status'"
+func (fds *_FieldsMap) columnComment(idx int) string {
+
+	if _, pg := fds.dialect.(postgresDialect); !pg || len(fds.fields[idx].comment) == 0 {
+		return ""
+	}
+
+	return "COMMENT ON COLUMN " + fds.quoteTable() + "." + fds.quote(fds.fields[idx].Tag) +
+		" IS " + quoteString(fds.fields[idx].comment)
+}
+
+// quoteString single quoted SQL string literal
+// example: quoteString("it's") returns "'it''s'"
+func quoteString(s string) string {
+
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// tableColumns column names of table in db
+func (fds *_FieldsMap) tableColumns(ctx context.Context,
+	exec Executor) ([]string, error) {
+
+	var cols []string
+	sqlstr := "SELECT * FROM " + fds.quoteTable() + " WHERE 1 = 0"
+	err := fds.queryRows(ctx, exec, sqlstr, nil, func(rs *sql.Rows) error {
+		var err error
+		cols, err = rs.Columns()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cols, nil
+}
+
+// CreateTableSQL CREATE TABLE IF NOT EXISTS with a column of each field
+// (select-only fields omitted, see columnDef) and PRIMARY KEY,
+// on Postgres followed by a COMMENT ON COLUMN statement of each
+// commented field, statements are separated by ";\n"
+// example:"CREATE TABLE IF NOT EXISTS `test_table` (\n  `field_key` VARCHAR(255) NOT NULL,\n  ...
+// \n  PRIMARY KEY (`field_key`)\n)"
+func (fds *_FieldsMap) CreateTableSQL() string {
+
+	return strings.Join(fds.createTableSQLs(), ";\n")
+}
+
+// createTableSQLs statements of CreateTableSQL
+func (fds *_FieldsMap) createTableSQLs() []string {
+
+	var defs []string
+	var comments []string
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if len(fds.fields[i].expr) > 0 {
+			continue
+		}
+		defs = append(defs, fds.columnDef(i))
+		if comment := fds.columnComment(i); len(comment) > 0 {
+			comments = append(comments, comment)
+		}
+	}
+
+	var pks []string
+	for i, plen := 0, len(fds.pks); i < plen; i++ {
+		pks = append(pks, fds.quote(fds.fields[fds.pks[i]].Tag))
+	}
+	defs = append(defs, "PRIMARY KEY ("+strings.Join(pks, ", ")+")")
+
+	create := "CREATE TABLE IF NOT EXISTS " + fds.quoteTable() + " (\n  " +
+		strings.Join(defs, ",\n  ") + "\n)"
+
+	return append([]string{create}, comments...)
+}
+
+// SQLCreateTable exec statements of CreateTableSQL on tx or db, one by one
+func (fds *_FieldsMap) SQLCreateTable(ctx context.Context, tx *sql.Tx,
+	db *sql.DB) error {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return err
+	}
+
+	sqlstrs := fds.createTableSQLs()
+	for i, slen := 0, len(sqlstrs); i < slen; i++ {
+		err = fds.execDDL(ctx, exec, sqlstrs[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// execDDL exec sqlstr without args, not rebound: DDL has no placeholders
+// and may hold '?' in string literals
+func (fds *_FieldsMap) execDDL(ctx context.Context, exec Executor, sqlstr string) error {
+
+	done := fds.logStart(ctx, sqlstr, nil)
+	_, err := exec.ExecContext(ctx, sqlstr)
+	err = classifyErr(err)
+	done(err)
+
+	return err
+}
+
+// SQLAlterTableAddMissing add columns of fields missing in table,
+// return ALTER statements executed. Columns are never dropped or modified.
+// example:"ALTER TABLE `test_table` ADD COLUMN `field_fou` DOUBLE"
+func (fds *_FieldsMap) SQLAlterTableAddMissing(ctx context.Context, tx *sql.Tx,
+	db *sql.DB) ([]string, error) {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	cols, err := fds.tableColumns(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
+
+	stmts := []string{}
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if len(fds.fields[i].expr) > 0 {
+			continue
+		}
+		found := false
+		for j, clen := 0, len(cols); j < clen; j++ {
+			if strings.EqualFold(cols[j], fds.fields[i].Tag) {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		sqlstrs := []string{"ALTER TABLE " + fds.quoteTable() + " ADD COLUMN " + fds.columnDef(i)}
+		if comment := fds.columnComment(i); len(comment) > 0 {
+			sqlstrs = append(sqlstrs, comment)
+		}
+		for j, slen := 0, len(sqlstrs); j < slen; j++ {
+			err = fds.execDDL(ctx, exec, sqlstrs[j])
+			if err != nil {
+				return stmts, err
+			}
+			stmts = append(stmts, sqlstrs[j])
+		}
+	}
+
+	return stmts, nil
+}
@@ -0,0 +1,203 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSQLAlterTableAddMissing(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return &fakeResult{cols: []string{"field_key", "FIELD_ONE", "field_thr", "legacy"}}
+		}
+		return &fakeResult{}
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	stmts, err := fm.SQLAlterTableAddMissing(context.Background(), nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"ALTER TABLE `test_table` ADD COLUMN `field_two` TINYINT(1)",
+		"ALTER TABLE `test_table` ADD COLUMN `field_fou` DOUBLE",
+	}
+	if strings.Join(stmts, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %v, want %v", stmts, want)
+	}
+
+	for _, q := range fdb.Queries() {
+		if strings.Contains(q.sql, "DROP") || strings.Contains(q.sql, "legacy") {
+			t.Errorf("must never drop columns: %q", q.sql)
+		}
+	}
+	if q := fdb.LastQuery(); q.sql != want[1] {
+		t.Errorf("last executed %q", q.sql)
+	}
+}
+
+func TestColumnDefComment(t *testing.T) {
+
+	type orderRow struct {
+		ID     int64  `sql:"id"`
+		Status string `sql:"status,comment='order status, it''s shown to users'"`
+	}
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{cols: []string{"id"}}
+	})
+	defer db.Close()
+
+	fm, _ := NewFieldsMap("orders", &orderRow{})
+	stmts, err := fm.SQLAlterTableAddMissing(context.Background(), nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "ALTER TABLE `orders` ADD COLUMN `status` VARCHAR(255) " +
+		"COMMENT 'order status, it''s shown to users'"
+	if len(stmts) != 1 || stmts[0] != want {
+		t.Errorf("got %v, want %s", stmts, want)
+	}
+
+	pg, _ := NewFieldsMapWithDialect("orders", &orderRow{}, Postgres)
+	stmts, err = pg.SQLAlterTableAddMissing(context.Background(), nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wants := []string{
+		`ALTER TABLE "orders" ADD COLUMN "status" VARCHAR(255)`,
+		`COMMENT ON COLUMN "orders"."status" IS 'order status, it''s shown to users'`,
+	}
+	if strings.Join(stmts, "\n") != strings.Join(wants, "\n") {
+		t.Errorf("got %v, want %v", stmts, wants)
+	}
+}
+
+func TestCreateTableSQLPostgres(t *testing.T) {
+
+	type userRow struct {
+		ID      int64      `sql:"id,pk,auto"`
+		Name    string     `sql:"name,comment='display name'"`
+		Balance float64    `sql:"balance"`
+		Count   uint64     `sql:"count"`
+		Active  bool       `sql:"active"`
+		Born    time.Time  `sql:"born,comment='birth day'"`
+		Left    *time.Time `sql:"left_at"`
+	}
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+
+	fm, _ := NewFieldsMapWithDialect("users", &userRow{}, Postgres)
+	wants := []string{
+		"CREATE TABLE IF NOT EXISTS \"users\" (\n" +
+			"  \"id\" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,\n" +
+			"  \"name\" VARCHAR(255),\n" +
+			"  \"balance\" DOUBLE PRECISION,\n" +
+			"  \"count\" NUMERIC(20),\n" +
+			"  \"active\" BOOLEAN,\n" +
+			"  \"born\" TIMESTAMP,\n" +
+			"  \"left_at\" TIMESTAMP,\n" +
+			"  PRIMARY KEY (\"id\")\n" +
+			")",
+		`COMMENT ON COLUMN "users"."name" IS 'display name'`,
+		`COMMENT ON COLUMN "users"."born" IS 'birth day'`,
+	}
+	if s := fm.CreateTableSQL(); s != strings.Join(wants, ";\n") {
+		t.Errorf("got\n%s\nwant\n%s", s, strings.Join(wants, ";\n"))
+	}
+
+	if err := fm.SQLCreateTable(context.Background(), nil, db); err != nil {
+		t.Fatal(err)
+	}
+	queries := fdb.Queries()
+	if len(queries) != len(wants) {
+		t.Fatalf("got %d statements, want %d", len(queries), len(wants))
+	}
+	for i, q := range queries {
+		if q.sql != wants[i] {
+			t.Errorf("statement %d got %q, want %q", i, q.sql, wants[i])
+		}
+	}
+}
+
+func TestCreateTableSQL(t *testing.T) {
+
+	type userRow struct {
+		ID      int64      `sql:"id,pk,auto"`
+		Name    string     `sql:"name,type=VARCHAR(64)"`
+		Bio     string     `sql:"bio,type=TEXT,comment='about me'"`
+		Balance float64    `sql:"balance,type='DECIMAL(10,2)'"`
+		Active  bool       `sql:"active"`
+		Born    time.Time  `sql:"born"`
+		Left    *time.Time `sql:"left_at"`
+		Total   int64      `sql:"total,expr=COUNT(*)"`
+	}
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	row := userRow{Name: "ann"}
+	fm, err := NewFieldsMap("users", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "CREATE TABLE IF NOT EXISTS `users` (\n" +
+		"  `id` BIGINT NOT NULL AUTO_INCREMENT,\n" +
+		"  `name` VARCHAR(64),\n" +
+		"  `bio` TEXT COMMENT 'about me',\n" +
+		"  `balance` DECIMAL(10,2),\n" +
+		"  `active` TINYINT(1),\n" +
+		"  `born` DATETIME,\n" +
+		"  `left_at` DATETIME,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		")"
+	if s := fm.CreateTableSQL(); s != want {
+		t.Errorf("got\n%s\nwant\n%s", s, want)
+	}
+
+	if err := fm.SQLCreateTable(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.sql != want {
+		t.Errorf("executed %q", q.sql)
+	}
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, "INSERT INTO `users` ( `name`, `bio`, `balance`, `active`, `born`, `left_at` )") {
+		t.Errorf("insert should match created columns, got %q", q.sql)
+	}
+
+	var comp struct {
+		Tenant string `sql:"tenant,pk"`
+		User   int64  `sql:"user,pk,auto"`
+	}
+	pg, _ := NewFieldsMapWithDialect("members", &comp, Postgres)
+	want = "CREATE TABLE IF NOT EXISTS \"members\" (\n" +
+		"  \"tenant\" VARCHAR(255) NOT NULL,\n" +
+		"  \"user\" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,\n" +
+		"  PRIMARY KEY (\"tenant\", \"user\")\n" +
+		")"
+	if s := pg.CreateTableSQL(); s != want {
+		t.Errorf("got\n%s\nwant\n%s", s, want)
+	}
+
+	var bad struct {
+		ID int64 `sql:"id,type="`
+	}
+	if _, err := NewFieldsMap("bad", &bad); err == nil {
+		t.Error("want error for empty type option")
+	}
+}
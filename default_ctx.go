@@ -0,0 +1,116 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SetDefaultContext set context used by *DefaultCtx methods
+func (fds *_FieldsMap) SetDefaultContext(ctx context.Context) {
+
+	fds.ctx = ctx
+}
+
+// DefaultContext context set by SetDefaultContext
+func (fds *_FieldsMap) DefaultContext() (context.Context, error) {
+
+	if fds.ctx == nil {
+		return nil, ErrNoDefaultContext
+	}
+
+	return fds.ctx, nil
+}
+
+// SQLLockByPriKeyDefaultCtx SQLLockByPriKey with default context
+func (fds *_FieldsMap) SQLLockByPriKeyDefaultCtx(tx *sql.Tx,
+	db *sql.DB) (interface{}, error) {
+
+	ctx, err := fds.DefaultContext()
+	if err != nil {
+		return nil, err
+	}
+
+	return fds.SQLLockByPriKey(ctx, tx, db)
+}
+
+// SQLSelectByPriKeyDefaultCtx SQLSelectByPriKey with default context
+func (fds *_FieldsMap) SQLSelectByPriKeyDefaultCtx(tx *sql.Tx,
+	db *sql.DB) (interface{}, error) {
+
+	ctx, err := fds.DefaultContext()
+	if err != nil {
+		return nil, err
+	}
+
+	return fds.SQLSelectByPriKey(ctx, tx, db)
+}
+
+// SQLSelectRowsByFieldNameInDBDefaultCtx SQLSelectRowsByFieldNameInDB
+// with default context
+func (fds *_FieldsMap) SQLSelectRowsByFieldNameInDBDefaultCtx(tx *sql.Tx,
+	db *sql.DB, nameInDB string) ([]interface{}, error) {
+
+	ctx, err := fds.DefaultContext()
+	if err != nil {
+		return nil, err
+	}
+
+	return fds.SQLSelectRowsByFieldNameInDB(ctx, tx, db, nameInDB)
+}
+
+// SQLSelectAllRowsDefaultCtx SQLSelectAllRows with default context
+func (fds *_FieldsMap) SQLSelectAllRowsDefaultCtx(tx *sql.Tx,
+	db *sql.DB) ([]interface{}, error) {
+
+	ctx, err := fds.DefaultContext()
+	if err != nil {
+		return nil, err
+	}
+
+	return fds.SQLSelectAllRows(ctx, tx, db)
+}
+
+// SQLInsertDefaultCtx SQLInsert with default context
+func (fds *_FieldsMap) SQLInsertDefaultCtx(tx *sql.Tx, db *sql.DB) error {
+
+	ctx, err := fds.DefaultContext()
+	if err != nil {
+		return err
+	}
+
+	return fds.SQLInsert(ctx, tx, db)
+}
+
+// SQLUpdateByPriKeyDefaultCtx SQLUpdateByPriKey with default context
+func (fds *_FieldsMap) SQLUpdateByPriKeyDefaultCtx(tx *sql.Tx, db *sql.DB) error {
+
+	ctx, err := fds.DefaultContext()
+	if err != nil {
+		return err
+	}
+
+	return fds.SQLUpdateByPriKey(ctx, tx, db)
+}
+
+// SQLDeleteByPriKeyDefaultCtx SQLDeleteByPriKey with default context
+func (fds *_FieldsMap) SQLDeleteByPriKeyDefaultCtx(tx *sql.Tx, db *sql.DB) error {
+
+	ctx, err := fds.DefaultContext()
+	if err != nil {
+		return err
+	}
+
+	return fds.SQLDeleteByPriKey(ctx, tx, db)
+}
+
+// SQLUpdateByCondDefaultCtx SQLUpdateByCond with default context
+func (fds *_FieldsMap) SQLUpdateByCondDefaultCtx(tx *sql.Tx, db *sql.DB,
+	extStr string, args ...interface{}) error {
+
+	ctx, err := fds.DefaultContext()
+	if err != nil {
+		return err
+	}
+
+	return fds.SQLUpdateByCond(ctx, tx, db, extStr, args...)
+}
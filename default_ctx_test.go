@@ -0,0 +1,36 @@
+package sqlmapper
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDefaultContext(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+
+	row := DemoRow{FieldKey: "key001"}
+	fm, err := NewFieldsMap(table, &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fm.SQLDeleteByPriKeyDefaultCtx(nil, db)
+	if !errors.Is(err, ErrNoDefaultContext) {
+		t.Fatalf("got %v, want ErrNoDefaultContext", err)
+	}
+	if len(fdb.Queries()) != 0 {
+		t.Fatal("no sql should run without default context")
+	}
+
+	fm.SetDefaultContext(context.Background())
+	err = fm.SQLDeleteByPriKeyDefaultCtx(nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.sql != "DELETE FROM `test_table`  where `field_key` = ? " {
+		t.Errorf("unexpected sql %q", q.sql)
+	}
+}
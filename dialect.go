@@ -0,0 +1,138 @@
+package sqlmapper
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect identifier quoting and bind placeholders of a database,
+// statements are generated with `?` placeholders (extStr too)
+// which are rendered by Placeholder before prepare
+type Dialect interface {
+	// Quote quote an identifier, e.g. `name` or "name"
+	Quote(ident string) string
+	// Placeholder placeholder of the n-th arg (from 1), e.g. ? or $1
+	Placeholder(n int) string
+}
+
+var (
+	// MySQL `name` and ?, the default Dialect
+	MySQL Dialect = mysqlDialect{}
+	// Postgres "name" and $1, $2, ...
+	Postgres Dialect = postgresDialect{}
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Quote(ident string) string {
+	return "`" + strings.Replace(ident, "`", "``", -1) + "`"
+}
+
+func (mysqlDialect) Placeholder(n int) string {
+	return "?"
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Quote(ident string) string {
+	return `"` + strings.Replace(ident, `"`, `""`, -1) + `"`
+}
+
+func (postgresDialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// NewFieldsMapWithDialect new Fields generating SQL of dialect,
+// NewFieldsMap is MySQL
+// example: fm, err := NewFieldsMapWithDialect("test_table", &row, sqlmapper.Postgres)
+func NewFieldsMapWithDialect(table string, objptr interface{},
+	dialect Dialect) (FieldsMap, error) {
+
+	fm, err := NewFieldsMap(table, objptr)
+	if err != nil {
+		return nil, err
+	}
+
+	if dialect != nil {
+		fm.(*_FieldsMap).dialect = dialect
+	}
+
+	return fm, nil
+}
+
+// quote quote identifier by dialect of fds
+func (fds *_FieldsMap) quote(ident string) string {
+
+	return fds.dialect.Quote(ident)
+}
+
+// quoteTable quoted table of fds, schema and name quoted apart
+// example:"`db`.`test_table`"
+func (fds *_FieldsMap) quoteTable() string {
+
+	parts := strings.Split(fds.table, ".")
+	for i, plen := 0, len(parts); i < plen; i++ {
+		parts[i] = fds.quote(parts[i])
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// rebind render `?` placeholders of sqlstr by dialect of fds,
+// `?` in quoted strings and identifiers are kept
+func (fds *_FieldsMap) rebind(sqlstr string) string {
+
+	if fds.dialect.Placeholder(1) == "?" {
+		return sqlstr
+	}
+
+	var b strings.Builder
+	var quote byte
+	n := 0
+	for i, slen := 0, len(sqlstr); i < slen; i++ {
+		c := sqlstr[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			n++
+			b.WriteString(fds.dialect.Placeholder(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// View FieldsMap of the same Object(struct) on another table and dialect,
+// from the layout parsed for the struct type, options of fds are kept,
+// empty table or nil dialect keeps that of fds,
+// so one object can be read from one database and written to another
+// example: pg, err := fds.View("test_table_v2", sqlmapper.Postgres)
+func (fds *_FieldsMap) View(table string, dialect Dialect) (FieldsMap, error) {
+
+	if len(table) > 0 {
+		if err := checkTable(table); err != nil {
+			return nil, err
+		}
+	}
+
+	view, err := fds.newRowMap(fds.objptr)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(table) > 0 {
+		view.table = table
+	}
+	if dialect != nil {
+		view.dialect = dialect
+	}
+
+	return view, nil
+}
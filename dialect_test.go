@@ -0,0 +1,208 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostgresDialect(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "key001"}
+	fm, err := NewFieldsMapWithDialect(table, &row, Postgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := fm.SQLFieldsStr(); s != ` "field_key", "field_one", "field_two", "field_thr", "field_fou" ` {
+		t.Errorf("unexpected fields %q", s)
+	}
+
+	cases := []struct {
+		run  func() error
+		want string
+	}{
+		{func() error { return fm.SQLInsert(ctx, nil, db) },
+			`INSERT INTO "test_table" ( "field_key", "field_one", "field_two", "field_thr", "field_fou" ) VALUES ($1, $2, $3, $4, $5)`},
+		{func() error { return fm.SQLUpdateByPriKey(ctx, nil, db) },
+			`UPDATE "test_table" SET  "field_key" = $1, "field_one" = $2, "field_two" = $3, "field_thr" = $4, "field_fou" = $5  where "field_key" = $6 `},
+		{func() error { return fm.SQLDeleteByPriKey(ctx, nil, db) },
+			`DELETE FROM "test_table"  where "field_key" = $1 `},
+		{func() error { return fm.SQLUpdateByCond(ctx, nil, db, " where field_one = '?' and field_thr > ? ", 1) },
+			`UPDATE "test_table" SET  "field_one" = $1, "field_two" = $2, "field_thr" = $3, "field_fou" = $4  where field_one = '?' and field_thr > $5 `},
+	}
+	for i, c := range cases {
+		if err := c.run(); err != nil {
+			t.Fatal(err)
+		}
+		if q := fdb.LastQuery(); q.sql != c.want {
+			t.Errorf("case %d got %q, want %q", i, q.sql, c.want)
+		}
+	}
+
+	var mrow DemoRow
+	mfm, _ := NewFieldsMapWithDialect(table, &mrow, nil)
+	if s := mfm.SQLFieldsStrForSet(); s != " `field_key` = ?, `field_one` = ?, `field_two` = ?, `field_thr` = ?, `field_fou` = ? " {
+		t.Errorf("nil dialect should be MySQL, got %q", s)
+	}
+}
+
+func TestView(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return demoRowsResult(1)
+		}
+		return nil
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "keya"}
+	fm, _ := NewFieldsMap(table, &row)
+	fm.SetPriority(LowPriority)
+
+	pg, err := fm.View("test_table_v2", Postgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if err := pg.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+
+	q := fdb.LastQuery()
+	want := `INSERT INTO "test_table_v2" ( "field_key", "field_one", "field_two", "field_thr", "field_fou" ) VALUES ($1, $2, $3, $4, $5)`
+	if q.sql != want {
+		t.Errorf("got %q, want %q", q.sql, want)
+	}
+	if q.args[1] != "one" {
+		t.Errorf("view should share the object, got %v", q.args)
+	}
+
+	same, _ := fm.View("", nil)
+	if s := same.SQLFieldsStr(); s != fm.SQLFieldsStr() {
+		t.Errorf("empty view should keep dialect, got %q", s)
+	}
+}
+
+// mysqlOnly SQL that is valid on MySQL only
+var mysqlOnly = regexp.MustCompile("`|\\?|RAND\\(\\)|HIGH_PRIORITY|LOW_PRIORITY|MODIFY COLUMN|" +
+	"DROP TEMPORARY|COMMENT '|ON DUPLICATE KEY|AUTO_INCREMENT|UNSIGNED|TINYINT|DATETIME|" +
+	`DOUBLE($|[^ ]| [^P])|COUNT\(DISTINCT "[^"]*", `)
+
+func TestPostgresDialectAllSQL(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		switch {
+		case strings.Contains(q, "WHERE 1 = 0"):
+			return &fakeResult{cols: []string{"field_key", "field_thr"},
+				types: []string{"VARCHAR", "VARCHAR"}}
+		case strings.Contains(q, "COUNT(") || strings.HasPrefix(q, "SELECT 1"):
+			return &fakeResult{cols: []string{"n"}, rows: [][]driver.Value{{int64(1)}}}
+		case strings.HasPrefix(q, "SELECT") || strings.Contains(q, "RETURNING"):
+			return demoRowsResult(2)
+		}
+		return &fakeResult{affected: 1}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "keya", FieldOne: "one"}
+	fm, _ := NewFieldsMapWithDialect(table, &row, Postgres)
+	fm.SetKeysInThreshold(1)
+	keys := []interface{}{"keya", "keyb"}
+	other := DemoRow{FieldKey: "keyb"}
+
+	var texts []string
+	for _, priority := range []Priority{HighPriority, LowPriority} {
+		fm.SetPriority(priority)
+
+		fm.SQLInsert(ctx, nil, db)
+		fm.SQLInsertReturning(ctx, nil, db)
+		fm.SQLInsertValues(ctx, nil, db, []interface{}{"keyc", "one", true, int64(3), 1.5})
+		fm.SQLInsertBatch(ctx, nil, db, []interface{}{&row, &other})
+		fm.SQLUpsert(ctx, nil, db)
+		fm.SQLSelectByPriKey(ctx, nil, db)
+		fm.SQLLockByPriKey(ctx, nil, db)
+		fm.SQLSelectRowsByFieldNameInDB(ctx, nil, db, "field_one")
+		fm.SQLSelectByFields(ctx, nil, db, map[string]interface{}{"field_one": "one"})
+		fm.SQLSelectWhere(ctx, nil, db, And(Eq("field_one", "one"), Gt("field_thr", 1)))
+		fm.SQLSelectByLike(ctx, nil, db, "field_one", "o%")
+		fm.SQLSelectBetween(ctx, nil, db, "field_thr", 1, 9)
+		fm.SQLSelect(ctx, nil, db, "")
+		fm.SQLSelectAllRows(ctx, nil, db)
+		fm.SQLSelectPage(ctx, nil, db, 10, 20)
+		fm.SQLSelectAllRowsOrdered(ctx, nil, db, []OrderClause{{NameInDB: "field_thr", Desc: true}})
+		fm.SQLSelectByPriKeys(ctx, nil, db, keys)
+		fm.SQLSelectByPriKeys(ctx, nil, db, keys[:1])
+		fm.SQLSelectMapByPriKeys(ctx, nil, db, keys)
+		fm.SQLSelectColumns(ctx, nil, db, []string{"field_key", "field_one"}, "")
+		fm.SQLSelectRandom(ctx, nil, db, 2, "")
+		fm.SQLSelectGroupBy(ctx, nil, db, "field_one", "")
+		fm.SQLSelectEach(ctx, nil, db, "", func(obj interface{}) error { return nil })
+		fm.SQLForEachRow(ctx, nil, db, 10, func(obj interface{}) error { return nil })
+		fm.SQLPluck(ctx, nil, db, "field_one", "")
+		fm.SQLCount(ctx, nil, db, "")
+		fm.SQLCountAll(ctx, nil, db)
+		fm.SQLCountDistinct(ctx, nil, db, []string{"field_one", "field_two"}, "")
+		fm.SQLExistsByPriKey(ctx, nil, db)
+		fm.SQLUpdateByPriKey(ctx, nil, db)
+		fm.SQLUpdateManyByPriKey(ctx, nil, db, []interface{}{&row, &other})
+		fm.SQLUpdateFieldsByPriKeys(ctx, nil, db, []string{"field_one"}, keys)
+		fm.SQLUpdateByCond(ctx, nil, db, "")
+		fm.SQLUpdateByCondReturningKeys(ctx, nil, db, "")
+		fm.SQLIncrementByPriKey(ctx, nil, db, "field_thr", 1)
+		fm.SQLDeleteByPriKeys(ctx, nil, db, keys)
+		fm.SQLDeleteByPriKey(ctx, nil, db)
+		fm.SQLAlterTableAddMissing(ctx, nil, db)
+		fm.SQLCreateTable(ctx, nil, db)
+		var serr *SchemaError
+		if err := fm.SQLValidateSchema(ctx, nil, db); !errors.As(err, &serr) {
+			t.Fatalf("want *SchemaError, got %v", err)
+		}
+		for _, issue := range serr.Issues {
+			texts = append(texts, issue.Fix)
+		}
+
+		texts = append(texts, fm.CreateTableSQL(), fm.SelectSQL(""), fm.InsertSQL(),
+			fm.UpdateSQL(""), fm.DeleteSQL(""))
+	}
+
+	type docRow struct {
+		ID     int64      `sql:"id,pk,auto"`
+		Status string     `sql:"status,comment='order status'"`
+		Score  float64    `sql:"score"`
+		Count  uint64     `sql:"count"`
+		Active bool       `sql:"active"`
+		Born   *time.Time `sql:"born"`
+	}
+	dfm, _ := NewFieldsMapWithDialect("docs", &docRow{}, Postgres)
+	dfm.SQLCreateTable(ctx, nil, db)
+	dfm.SQLAlterTableAddMissing(ctx, nil, db)
+	texts = append(texts, dfm.CreateTableSQL())
+
+	queries := fdb.Queries()
+	if len(queries) < 80 {
+		t.Errorf("only %d statements rendered", len(queries))
+	}
+	for _, q := range queries {
+		texts = append(texts, q.sql)
+	}
+	for _, s := range texts {
+		if m := mysqlOnly.FindString(s); len(m) > 0 {
+			t.Errorf("MySQL only %q in Postgres SQL %q", m, s)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+)
+
+// Set set field nameInDB of Object(struct) to value and mark it dirty,
+// once a field is Set, SQLUpdateByPriKey updates only dirty fields
+// (and row hash field) until ClearDirty, dirty fields are cleared
+// by a successful SQLUpdateByPriKey or select of the row by fds.
+// primary key and select-only fields can not be Set,
+// value must be assignable to the field (an int to a signed integer field),
+// nil for a pointer field
+// example: fds.Set("field_thr", int64(3))
+func (fds *_FieldsMap) Set(nameInDB string, value interface{}) error {
+
+	idx := fds.fieldIndex(nameInDB)
+	if idx < 0 {
+		return errors.New("no field match `sql` tag:" + nameInDB)
+	}
+	if fds.isPriKey(idx) {
+		return errors.New("primary key can not be Set:" + nameInDB)
+	}
+	if len(fds.fields[idx].expr) > 0 {
+		return errors.New("select-only field can not be Set:" + nameInDB)
+	}
+
+	fv := reflect.ValueOf(fds.fields[idx].Addr).Elem()
+	if value == nil && fds.fields[idx].ptr {
+		fv.Set(reflect.Zero(fv.Type()))
+	} else {
+		vv, ok := assignValue(fv.Type(), value)
+		if !ok {
+			return errors.New("value is not " + fv.Type().String() + ": " + nameInDB)
+		}
+		fv.Set(vv)
+	}
+
+	if fds.dirty == nil {
+		fds.dirty = make([]bool, len(fds.fields))
+	}
+	fds.dirty[idx] = true
+
+	return nil
+}
+
+// DirtyFields names in db of fields Set since loaded or updated,
+// in field order
+func (fds *_FieldsMap) DirtyFields() []string {
+
+	var names []string
+	for i, dlen := 0, len(fds.dirty); i < dlen; i++ {
+		if fds.dirty[i] {
+			names = append(names, fds.fields[i].Tag)
+		}
+	}
+
+	return names
+}
+
+// ClearDirty forget dirty fields, SQLUpdateByPriKey updates all fields again
+func (fds *_FieldsMap) ClearDirty() {
+
+	fds.dirty = nil
+}
+
+// updateDirty UPDATE only dirty fields & row hash field by primary key,
+// call with fds.dirty not nil
+func (fds *_FieldsMap) updateDirty(ctx context.Context, exec Executor) (sql.Result, error) {
+
+	var sets string
+	var values []interface{}
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if !fds.dirty[i] && !fds.fields[i].hash {
+			continue
+		}
+		if len(sets) > 0 {
+			sets += ", "
+		}
+		sets += fds.quote(fds.fields[i].Tag) + " = ?"
+		values = append(values, fds.GetFieldValue(i))
+	}
+
+	extStr, args := fds.scoped(" where "+fds.priKeyCond()+" ", fds.priKeyValues()...)
+	values = append(values, args...)
+	sqlstr := fds.verb("UPDATE") + fds.quoteTable() + " SET " + sets + extStr
+	res, err := fds.execSQL(ctx, exec, sqlstr, values...)
+	if err != nil {
+		return nil, err
+	}
+	if err := fds.checkAffected(res); err != nil {
+		return nil, err
+	}
+
+	fds.ClearDirty()
+	return res, nil
+}
@@ -0,0 +1,82 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestSetDirtyFields(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return demoRowsResult(1)
+		}
+		return nil
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "keya"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	if err := fm.Set("field_thr", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := fm.Set("field_one", "two"); err != nil {
+		t.Fatal(err)
+	}
+	if row.FieldThr != 3 || row.FieldOne != "two" {
+		t.Errorf("Set did not update object: %+v", row)
+	}
+	if d := fm.DirtyFields(); len(d) != 2 || d[0] != "field_one" || d[1] != "field_thr" {
+		t.Errorf("unexpected dirty fields %v", d)
+	}
+
+	err := fm.SQLUpdateByPriKey(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := fdb.LastQuery()
+	if q.sql != "UPDATE `test_table` SET `field_one` = ?, `field_thr` = ? where `field_key` = ? " ||
+		len(q.args) != 3 || q.args[0] != "two" || q.args[1] != int64(3) || q.args[2] != "keya" {
+		t.Errorf("update got %q %v", q.sql, q.args)
+	}
+	if len(fm.DirtyFields()) != 0 {
+		t.Error("dirty fields should be cleared by update")
+	}
+
+	// without dirty fields, all fields are updated
+	err = fm.SQLUpdateByPriKey(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); len(q.args) != 6 {
+		t.Errorf("want full update, got %q", q.sql)
+	}
+
+	fm.Set("field_two", false)
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if len(fm.DirtyFields()) != 0 {
+		t.Error("dirty fields should be cleared by select")
+	}
+
+	if err := fm.Set("field_key", "keyb"); err == nil {
+		t.Error("want error setting primary key")
+	}
+	if err := fm.Set("field_thr", "x"); err == nil {
+		t.Error("want error for value of wrong type")
+	}
+	if err := fm.Set("field_one", 65); err == nil || row.FieldOne == "A" {
+		t.Errorf("int on string field must not be converted, got %q", row.FieldOne)
+	}
+	if err := fm.Set("field_thr", 2.9); err == nil {
+		t.Error("float on int field must not be truncated")
+	}
+	if err := fm.Set("no_field", 1); err == nil {
+		t.Error("want error for unknown field")
+	}
+}
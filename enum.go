@@ -0,0 +1,126 @@
+package sqlmapper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// enumTable bijective code <=> name table of an integer enum type
+type enumTable struct {
+	reftype reflect.Type
+	names   map[int64]string
+	codes   map[string]int64
+}
+
+// enumRegistry reflect.Type => *enumTable
+var enumRegistry sync.Map
+
+// RegisterEnum register an integer enum type with its code => name table,
+// fields of the type are bound as int code in db, codes not in table
+// are rejected on bind and scan.
+// Must be called before the first NewFieldsMap of structs using it.
+// example:
+// type Color int
+// RegisterEnum(Color(0), map[int64]string{0: "red", 1: "green", 2: "blue"})
+//
+func RegisterEnum(sample interface{}, names map[int64]string) error {
+
+	reftype := reflect.TypeOf(sample)
+	if reftype == nil || !isIntKind(reftype.Kind()) {
+		return errors.New("enum must be an integer type")
+	}
+
+	if len(names) == 0 {
+		return errors.New("enum " + reftype.String() + " has no value")
+	}
+
+	codes := make(map[string]int64, len(names))
+	for code, name := range names {
+		if _, ok := codes[name]; ok {
+			return errors.New("enum " + reftype.String() + " duplicate name: " + name)
+		}
+		codes[name] = code
+	}
+
+	enumRegistry.Store(reftype, &enumTable{
+		reftype: reftype,
+		names:   names,
+		codes:   codes,
+	})
+
+	return nil
+}
+
+// EnumName name of a registered enum value
+func EnumName(v interface{}) (string, error) {
+
+	et := lookupEnum(reflect.TypeOf(v))
+	if et == nil {
+		return "", fmt.Errorf("enum %T not registered", v)
+	}
+
+	code := reflect.ValueOf(v).Int()
+	name, ok := et.names[code]
+	if !ok {
+		return "", fmt.Errorf("invalid enum %T code: %d", v, code)
+	}
+
+	return name, nil
+}
+
+// EnumValue value of name for the registered enum type of sample
+// example: v, err := EnumValue(Color(0), "green") // v is Color(1)
+func EnumValue(sample interface{}, name string) (interface{}, error) {
+
+	et := lookupEnum(reflect.TypeOf(sample))
+	if et == nil {
+		return nil, fmt.Errorf("enum %T not registered", sample)
+	}
+
+	code, ok := et.codes[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid enum %T name: %s", sample, name)
+	}
+
+	v := reflect.New(et.reftype).Elem()
+	v.SetInt(code)
+	return v.Interface(), nil
+}
+
+// lookupEnum registered enum of type, nil if not registered
+func lookupEnum(reftype reflect.Type) *enumTable {
+
+	if reftype == nil {
+		return nil
+	}
+
+	v, ok := enumRegistry.Load(reftype)
+	if !ok {
+		return nil
+	}
+
+	return v.(*enumTable)
+}
+
+// check code in enum table
+func (et *enumTable) check(code int64) error {
+
+	if _, ok := et.names[code]; !ok {
+		return fmt.Errorf("invalid enum %s code: %d", et.reftype.String(), code)
+	}
+
+	return nil
+}
+
+func isIntKind(kind reflect.Kind) bool {
+
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+	}
+
+	return false
+}
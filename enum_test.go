@@ -0,0 +1,124 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+type testColor int
+
+const (
+	testRed testColor = iota
+	testGreen
+	testBlue
+)
+
+type enumRow struct {
+	ID    int64     `sql:"id"`
+	Color testColor `sql:"color"`
+}
+
+func init() {
+	err := RegisterEnum(testRed, map[int64]string{
+		int64(testRed):   "red",
+		int64(testGreen): "green",
+		int64(testBlue):  "blue",
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterEnumInvalid(t *testing.T) {
+
+	if err := RegisterEnum("red", map[int64]string{0: "red"}); err == nil {
+		t.Error("want error for non-integer enum")
+	}
+
+	type dupColor int
+	if err := RegisterEnum(dupColor(0), map[int64]string{0: "red", 1: "red"}); err == nil {
+		t.Error("want error for duplicate name")
+	}
+}
+
+func TestEnumNameValue(t *testing.T) {
+
+	name, err := EnumName(testBlue)
+	if err != nil || name != "blue" {
+		t.Errorf("got %q %v, want blue", name, err)
+	}
+
+	if _, err := EnumName(testColor(9)); err == nil {
+		t.Error("want error for invalid code")
+	}
+
+	v, err := EnumValue(testRed, "green")
+	if err != nil || v.(testColor) != testGreen {
+		t.Errorf("got %v %v, want green", v, err)
+	}
+}
+
+func TestEnumBind(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "color"},
+			rows: [][]driver.Value{{int64(1), int64(2)}},
+		}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := enumRow{ID: 1, Color: testGreen}
+	fm, err := NewFieldsMap("enum_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fm.SQLInsert(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args := fdb.LastQuery().args; args[1] != int64(1) {
+		t.Errorf("bind %v, want code 1", args[1])
+	}
+
+	_, err = fm.SQLSelectByPriKey(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.Color != testBlue {
+		t.Errorf("scan %v, want blue", row.Color)
+	}
+
+	row.Color = testColor(7)
+	n := len(fdb.Queries())
+	if err := fm.SQLInsert(ctx, nil, db); err == nil {
+		t.Error("want error binding invalid code")
+	}
+	if len(fdb.Queries()) != n {
+		t.Error("invalid code should not reach db")
+	}
+}
+
+func TestEnumScanInvalid(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "color"},
+			rows: [][]driver.Value{{int64(1), int64(5)}},
+		}
+	})
+	defer db.Close()
+
+	row := enumRow{ID: 1}
+	fm, err := NewFieldsMap("enum_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fm.SQLSelectAllRows(context.Background(), nil, db); err == nil {
+		t.Error("want error scanning invalid code")
+	}
+}
@@ -0,0 +1,155 @@
+package sqlmapper
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoDefaultContext default context not set by SetDefaultContext
+	ErrNoDefaultContext = errors.New("default context not set")
+
+	// ErrUnexpectedNull NULL scanned with ErrorOnNull policy
+	ErrUnexpectedNull = errors.New("unexpected NULL")
+
+	// ErrTooManyRows result has more rows than destination of SelectInto
+	ErrTooManyRows = errors.New("too many rows for destination")
+
+	// ErrSQLTooLarge statement exceeds SQLGuard
+	ErrSQLTooLarge = errors.New("sql too large")
+
+	// ErrNoRowsAffected strict SQLUpdateByPriKey matched no row
+	ErrNoRowsAffected = errors.New("no rows affected")
+
+	// ErrVersionConflict SQLUpdateByPriKey of a struct with version field
+	// matched no row, it is deleted or updated by others since read
+	ErrVersionConflict = errors.New("version conflict")
+
+	// ErrNotFound no row match the key of a select by key,
+	// the error is a *NotFoundError
+	ErrNotFound = errors.New("not found")
+
+	// ErrConnectionLost connection to db dropped (driver.ErrBadConn or
+	// sql.ErrConnDone), the operation may be retried on a new connection
+	ErrConnectionLost = errors.New("connection lost")
+)
+
+// connLostError wrap a driver error as ErrConnectionLost,
+// errors.Is matches both ErrConnectionLost and the driver error
+type connLostError struct {
+	err error
+}
+
+func (e *connLostError) Error() string {
+	return ErrConnectionLost.Error() + ": " + e.err.Error()
+}
+
+func (e *connLostError) Unwrap() error {
+	return e.err
+}
+
+func (e *connLostError) Is(target error) bool {
+	return target == ErrConnectionLost
+}
+
+// classifyErr wrap connection errors as ErrConnectionLost
+func classifyErr(err error) error {
+
+	if err == nil || errors.Is(err, ErrConnectionLost) {
+		return err
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return &connLostError{err: err}
+	}
+
+	return err
+}
+
+// PrepareError statement SQL failed to prepare,
+// errors.Is / errors.As match the driver error
+type PrepareError struct {
+	SQL string
+	Err error
+}
+
+func (e *PrepareError) Error() string {
+	return "prepare failed: " + e.SQL + ": " + e.Err.Error()
+}
+
+func (e *PrepareError) Unwrap() error {
+	return e.Err
+}
+
+// RowError error of the row at Index of a batch
+type RowError struct {
+	Index int
+	Obj   interface{}
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Index, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError rows failed in a batch, ordered by Index,
+// errors.Is / errors.As match error of any row
+type BatchError struct {
+	Rows []*RowError
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d rows of batch failed, first %v", len(e.Rows), e.Rows[0])
+}
+
+func (e *BatchError) Unwrap() []error {
+
+	errs := make([]error, len(e.Rows))
+	for i, rlen := 0, len(e.Rows); i < rlen; i++ {
+		errs[i] = e.Rows[i]
+	}
+
+	return errs
+}
+
+// NotFoundError no row of Table match Key,
+// errors.Is(err, ErrNotFound) and errors.Is(err, sql.ErrNoRows) are true
+type NotFoundError struct {
+	Table string
+	Type  string
+	Key   interface{}
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s: %s with key %v not found", e.Table, e.Type, e.Key)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return sql.ErrNoRows
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// notFound err as *NotFoundError of the primary key of fds
+// if it is sql.ErrNoRows, else err
+func (fds *_FieldsMap) notFound(err error) error {
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	var key interface{} = fds.PrimaryKeyValue()
+	if len(fds.pks) > 1 {
+		key = fds.priKeyValues()
+	}
+
+	return &NotFoundError{Table: fds.table, Type: fds.reftype.Name(), Key: key}
+}
@@ -0,0 +1,120 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestErrConnectionLost(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{err: driver.ErrBadConn}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "key001"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	err := fm.SQLUpdateByPriKey(ctx, nil, db)
+	if !errors.Is(err, ErrConnectionLost) || !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("exec got %v, want ErrConnectionLost wrapping ErrBadConn", err)
+	}
+
+	_, err = fm.SQLSelectAllRows(ctx, nil, db)
+	if !errors.Is(err, ErrConnectionLost) {
+		t.Errorf("query got %v, want ErrConnectionLost", err)
+	}
+}
+
+func TestErrConnectionLostConnDone(t *testing.T) {
+
+	db, _ := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	_, err = SelectAll[DemoRow](ctx, conn, table, "")
+	if !errors.Is(err, ErrConnectionLost) || !errors.Is(err, sql.ErrConnDone) {
+		t.Errorf("got %v, want ErrConnectionLost wrapping ErrConnDone", err)
+	}
+}
+
+func TestClassifyErrQueryError(t *testing.T) {
+
+	queryErr := errors.New("syntax error")
+	if err := classifyErr(queryErr); err != queryErr || errors.Is(err, ErrConnectionLost) {
+		t.Errorf("query error should pass through, got %v", err)
+	}
+
+	lost := classifyErr(driver.ErrBadConn)
+	if classifyErr(lost) != lost {
+		t.Error("classifyErr should not wrap twice")
+	}
+}
+
+func TestPrepareError(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	syntaxErr := errors.New("You have an error in your SQL syntax")
+	fdb.prepareErr = syntaxErr
+
+	row := DemoRow{FieldKey: "key001"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	_, err := fm.SQLSelectStmt(context.Background(), nil, db, " wher `field_thr` = ? ")
+	want := "prepare failed: SELECT  `field_key`, `field_one`, `field_two`, `field_thr`, `field_fou`  " +
+		"FROM `test_table`  wher `field_thr` = ? : You have an error in your SQL syntax"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got %v\nwant %s", err, want)
+	}
+
+	var perr *PrepareError
+	if !errors.As(err, &perr) || !errors.Is(err, syntaxErr) {
+		t.Errorf("want *PrepareError wrapping driver error, got %#v", err)
+	}
+}
+
+func TestErrNotFound(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{cols: demoRowsResult(0).cols}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "key404"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	_, err := fm.SQLSelectByPriKey(ctx, nil, db)
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("want ErrNotFound, got %v", err)
+	}
+	var nf *NotFoundError
+	if !errors.As(err, &nf) || nf.Table != table || nf.Type != "DemoRow" || nf.Key != "key404" {
+		t.Errorf("unexpected NotFoundError %+v", nf)
+	}
+
+	tx, _ := db.BeginTx(ctx, nil)
+	defer tx.Rollback()
+	if _, err := fm.SQLLockByPriKey(ctx, tx, nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("lock want ErrNotFound, got %v", err)
+	}
+
+	if _, err := SelectByPriKey[DemoRow](ctx, db, table, "key404"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("generic want ErrNotFound, got %v", err)
+	}
+
+	if errors.Is(sql.ErrNoRows, ErrNotFound) {
+		t.Error("plain sql.ErrNoRows should not be ErrNotFound")
+	}
+}
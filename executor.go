@@ -0,0 +1,254 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+)
+
+// Executor run sql on *sql.DB, *sql.Tx or *sql.Conn
+type Executor interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// errNilExecutor nil Executor passed to *On methods
+var errNilExecutor = errors.New("nil executor")
+
+var (
+	_ Executor = &sql.DB{}
+	_ Executor = &sql.Tx{}
+	_ Executor = &sql.Conn{}
+)
+
+// getExecutor prefer tx over db
+func getExecutor(tx *sql.Tx, db *sql.DB) (Executor, error) {
+
+	if tx != nil {
+		return tx, nil
+	}
+
+	if db != nil {
+		return db, nil
+	}
+
+	return nil, errors.New("tx & db both nil")
+}
+
+// SQLSelectByPriKeyOn SQLSelectByPriKey on exec, e.g. a *sql.Conn
+// for session-scoped work
+// example: conn, _ := db.Conn(ctx); fds.SQLSelectByPriKeyOn(ctx, conn)
+func (fds *_FieldsMap) SQLSelectByPriKeyOn(ctx context.Context,
+	exec Executor) (interface{}, error) {
+
+	if exec == nil {
+		return nil, errNilExecutor
+	}
+
+	return fds.selectByPriKey(ctx, exec)
+}
+
+// SQLSelectAllRowsOn SQLSelectAllRows on exec
+func (fds *_FieldsMap) SQLSelectAllRowsOn(ctx context.Context,
+	exec Executor) ([]interface{}, error) {
+
+	if exec == nil {
+		return nil, errNilExecutor
+	}
+
+	return fds.selectAllRows(ctx, exec)
+}
+
+// SQLInsertOn SQLInsert on exec
+func (fds *_FieldsMap) SQLInsertOn(ctx context.Context, exec Executor) error {
+
+	if exec == nil {
+		return errNilExecutor
+	}
+
+	_, err := fds.insert(ctx, exec, false)
+	return err
+}
+
+// SQLUpdateByPriKeyOn SQLUpdateByPriKey on exec
+func (fds *_FieldsMap) SQLUpdateByPriKeyOn(ctx context.Context, exec Executor) error {
+
+	if exec == nil {
+		return errNilExecutor
+	}
+
+	_, err := fds.update(ctx, exec)
+	return err
+}
+
+// SQLDeleteByPriKeyOn SQLDeleteByPriKey on exec
+func (fds *_FieldsMap) SQLDeleteByPriKeyOn(ctx context.Context, exec Executor) error {
+
+	if exec == nil {
+		return errNilExecutor
+	}
+
+	_, err := fds.deleteByPriKey(ctx, exec, false)
+	return err
+}
+
+// withTx run fn in tx, begin & commit a new one on db when tx is nil
+func withTx(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	fn func(tx *sql.Tx) error) error {
+
+	if tx != nil {
+		return fn(tx)
+	}
+
+	if db == nil {
+		return errors.New("tx & db both nil")
+	}
+
+	return RunInTx(ctx, db, nil, fn)
+}
+
+// RunInTx run fn in a new tx of db, commit if fn returns nil,
+// rollback if fn returns error or panics, the panic goes on after rollback
+// example: err := sqlmapper.RunInTx(ctx, db, nil, func(tx *sql.Tx) error {
+// 	if err := fm.SQLInsert(ctx, tx, nil); err != nil {
+// 		return err
+// 	}
+// 	return vm.SQLUpdateByPriKey(ctx, tx, nil)
+// })
+func RunInTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions,
+	fn func(tx *sql.Tx) error) error {
+
+	if db == nil {
+		return errors.New("nil db")
+	}
+
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	committed = true
+	return tx.Commit()
+}
+
+// prepare prepare statement on exec, placeholders rendered by dialect,
+// error is *PrepareError holding sqlstr
+func (fds *_FieldsMap) prepare(ctx context.Context, exec Executor,
+	sqlstr string) (*sql.Stmt, error) {
+
+	if err := fds.checkGuard(sqlstr); err != nil {
+		return nil, err
+	}
+	sqlstr = fds.rebind(sqlstr)
+
+	stmt, err := exec.PrepareContext(ctx, sqlstr)
+	if err != nil {
+		return nil, &PrepareError{SQL: sqlstr, Err: classifyErr(err)}
+	}
+
+	return stmt, nil
+}
+
+// execSQL prepare & exec sqlstr
+func (fds *_FieldsMap) execSQL(ctx context.Context, exec Executor,
+	sqlstr string, args ...interface{}) (sql.Result, error) {
+
+	fds.InvalidateQueryCache()
+	stmt, release, err := fds.prepareShared(ctx, exec, sqlstr)
+	if err != nil {
+		return nil, err
+	}
+	defer release() // must release stmt after stmt used
+
+	done := fds.logStart(ctx, sqlstr, args)
+	res, err := stmt.ExecContext(ctx, args...)
+	err = classifyErr(err)
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// queryRows prepare & query sqlstr, call fn with Rows,
+// Rows are closed after fn returned
+func (fds *_FieldsMap) queryRows(ctx context.Context, exec Executor,
+	sqlstr string, args []interface{}, fn func(rs *sql.Rows) error) error {
+
+	stmt, release, err := fds.prepareShared(ctx, exec, sqlstr)
+	if err != nil {
+		return err
+	}
+	defer release() // must release stmt after stmt used
+
+	done := fds.logStart(ctx, sqlstr, args)
+	rs, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		err = classifyErr(err)
+		done(err)
+		return err
+	}
+	defer rs.Close() // should close Rows after used
+
+	err = classifyErr(fn(rs))
+	done(err)
+	return err
+}
+
+// selectOne query sqlstr, scan the first row into fds,
+// sql.ErrNoRows if no row
+func (fds *_FieldsMap) selectOne(ctx context.Context, exec Executor,
+	sqlstr string, args ...interface{}) (interface{}, error) {
+
+	key := fds.cacheKey(scanByPosition, sqlstr, args)
+	if len(key) > 0 {
+		if objs, _, ok := fds.cacheGet(key); ok {
+			if len(objs) == 0 {
+				return nil, sql.ErrNoRows
+			}
+			reflect.ValueOf(fds.objptr).Elem().Set(reflect.ValueOf(objs[0]).Elem())
+			fds.ClearDirty()
+			return fds.objptr, nil
+		}
+	}
+
+	err := fds.queryRows(ctx, exec, sqlstr, args, func(rs *sql.Rows) error {
+		if !rs.Next() {
+			if err := rs.Err(); err != nil {
+				return err
+			}
+			return sql.ErrNoRows
+		}
+
+		return scanRow(rs, fds.GetFieldSaveAddrs()...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	objptr, err := fds.mapBack()
+	if err != nil {
+		return objptr, err
+	}
+	fds.ClearDirty()
+
+	if len(key) > 0 {
+		fds.cachePut(key, []interface{}{objptr}, nil)
+	}
+	return objptr, nil
+}
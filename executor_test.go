@@ -0,0 +1,109 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExecutorOnConn(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return demoRowsResult(1)
+		}
+		return &fakeResult{affected: 1}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	row := DemoRow{FieldKey: "keya"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	if _, err := fm.SQLSelectByPriKeyOn(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	if row.FieldOne != "one" {
+		t.Errorf("unexpected %+v", row)
+	}
+	if objs, err := fm.SQLSelectAllRowsOn(ctx, conn); err != nil || len(objs) != 1 {
+		t.Fatalf("got %v %v", objs, err)
+	}
+
+	row.FieldThr = 9
+	for _, run := range []func() error{
+		func() error { return fm.SQLInsertOn(ctx, conn) },
+		func() error { return fm.SQLUpdateByPriKeyOn(ctx, conn) },
+		func() error { return fm.SQLDeleteByPriKeyOn(ctx, conn) },
+	} {
+		if err := run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(fdb.Queries()); n != 5 {
+		t.Errorf("got %d queries, want 5", n)
+	}
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, "DELETE") {
+		t.Errorf("unexpected last query %q", q.sql)
+	}
+
+	if err := fm.SQLInsertOn(ctx, nil); err == nil {
+		t.Error("want error for nil executor")
+	}
+}
+
+func TestRunInTx(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "keya"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	lastSQL := func() string { return fdb.LastQuery().sql }
+
+	err := RunInTx(ctx, db, nil, func(tx *sql.Tx) error {
+		return fm.SQLInsert(ctx, tx, nil)
+	})
+	if err != nil || lastSQL() != "COMMIT" {
+		t.Errorf("want commit, got %v %q", err, lastSQL())
+	}
+
+	errFn := errors.New("fn failed")
+	err = RunInTx(ctx, db, nil, func(tx *sql.Tx) error {
+		fm.SQLInsert(ctx, tx, nil)
+		return errFn
+	})
+	if err != errFn || lastSQL() != "ROLLBACK" {
+		t.Errorf("want rollback on error, got %v %q", err, lastSQL())
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("panic should go on after rollback, got %v", r)
+			}
+		}()
+		RunInTx(ctx, db, nil, func(tx *sql.Tx) error {
+			fm.SQLInsert(ctx, tx, nil)
+			panic("boom")
+		})
+	}()
+	if lastSQL() != "ROLLBACK" {
+		t.Errorf("want rollback on panic, got %q", lastSQL())
+	}
+
+	if err := RunInTx(ctx, nil, nil, func(tx *sql.Tx) error { return nil }); err == nil {
+		t.Error("want error for nil db")
+	}
+}
@@ -0,0 +1,63 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+type lineRow struct {
+	ID    string  `sql:"id"`
+	Price float64 `sql:"price"`
+	Qty   int64   `sql:"qty"`
+	Total float64 `sql:"total,expr='price * qty'"`
+}
+
+func TestExprField(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "price", "qty", "total"},
+			rows: [][]driver.Value{{"id001", 1.5, int64(4), 6.0}},
+		}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := lineRow{ID: "id001"}
+	fm, err := NewFieldsMap("lines", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT  `id`, `price`, `qty`, (price * qty) AS `total`  FROM `lines`  where `id` = ? "
+	if q := fdb.LastQuery(); q.sql != want {
+		t.Errorf("got %q\nwant %q", q.sql, want)
+	}
+	if row.Total != 6 {
+		t.Errorf("total %v, want 6", row.Total)
+	}
+
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	want = "INSERT INTO `lines` ( `id`, `price`, `qty` ) VALUES (?, ?, ?)"
+	if q := fdb.LastQuery(); q.sql != want || len(q.args) != 3 {
+		t.Errorf("got %q %v\nwant %q", q.sql, q.args, want)
+	}
+
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	want = "UPDATE `lines` SET  `id` = ?, `price` = ?, `qty` = ?  where `id` = ? "
+	if q := fdb.LastQuery(); q.sql != want || len(q.args) != 4 {
+		t.Errorf("got %q %v\nwant %q", q.sql, q.args, want)
+	}
+
+	if err := fm.SQLUpsert(ctx, nil, db, "total"); err == nil {
+		t.Error("want error for upsert of select-only field")
+	}
+}
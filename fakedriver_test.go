@@ -0,0 +1,254 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+// fakeResult scripted result for one statement
+type fakeResult struct {
+	cols     []string
+	types    []string // DatabaseTypeName of cols, optional
+	rows     [][]driver.Value
+	affected int64
+	lastID   int64
+	rowsErr  error // returned by rows.Next after all rows
+	err      error // returned by exec/query
+}
+
+// fakeQuery one statement seen by the fake driver
+type fakeQuery struct {
+	sql  string
+	args []driver.Value
+}
+
+// fakeDB records every statement and answers it by handler
+type fakeDB struct {
+	mu         sync.Mutex
+	queries    []fakeQuery
+	prepares   []string
+	closes     []string
+	prepareErr error
+	handler    func(q string, args []driver.Value) *fakeResult
+}
+
+func newFakeDB(handler func(q string, args []driver.Value) *fakeResult) (*sql.DB, *fakeDB) {
+
+	fdb := &fakeDB{handler: handler}
+	return sql.OpenDB(fdb), fdb
+}
+
+func (fdb *fakeDB) record(q string, args []driver.Value) *fakeResult {
+
+	fdb.mu.Lock()
+	fdb.queries = append(fdb.queries, fakeQuery{sql: q, args: args})
+	handler := fdb.handler
+	fdb.mu.Unlock()
+
+	if handler == nil {
+		return &fakeResult{}
+	}
+	res := handler(q, args)
+	if res == nil {
+		return &fakeResult{}
+	}
+	return res
+}
+
+// Queries statements executed so far
+func (fdb *fakeDB) Queries() []fakeQuery {
+
+	fdb.mu.Lock()
+	defer fdb.mu.Unlock()
+
+	return append([]fakeQuery(nil), fdb.queries...)
+}
+
+// Prepares statements prepared so far
+func (fdb *fakeDB) Prepares() []string {
+
+	fdb.mu.Lock()
+	defer fdb.mu.Unlock()
+
+	return append([]string(nil), fdb.prepares...)
+}
+
+// Closes statements closed so far
+func (fdb *fakeDB) Closes() []string {
+
+	fdb.mu.Lock()
+	defer fdb.mu.Unlock()
+
+	return append([]string(nil), fdb.closes...)
+}
+
+// LastQuery last statement executed
+func (fdb *fakeDB) LastQuery() fakeQuery {
+
+	qs := fdb.Queries()
+	if len(qs) == 0 {
+		return fakeQuery{}
+	}
+	return qs[len(qs)-1]
+}
+
+func (fdb *fakeDB) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{db: fdb}, nil
+}
+
+func (fdb *fakeDB) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("fake driver: use sql.OpenDB")
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+
+	c.db.mu.Lock()
+	c.db.prepares = append(c.db.prepares, query)
+	err := c.db.prepareErr
+	c.db.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+
+	c.db.record("BEGIN", nil)
+	return &fakeTx{conn: c}, nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+}
+
+func (tx *fakeTx) Commit() error {
+
+	tx.conn.db.record("COMMIT", nil)
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+
+	tx.conn.db.record("ROLLBACK", nil)
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+
+	s.conn.db.mu.Lock()
+	s.conn.db.closes = append(s.conn.db.closes, s.query)
+	s.conn.db.mu.Unlock()
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+
+	res := s.conn.db.record(s.query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	return fakeExecResult{affected: res.affected, lastID: res.lastID}, nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+
+	res := s.conn.db.record(s.query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	return &fakeRows{res: res}, nil
+}
+
+type fakeExecResult struct {
+	affected int64
+	lastID   int64
+}
+
+func (r fakeExecResult) LastInsertId() (int64, error) {
+	return r.lastID, nil
+}
+
+func (r fakeExecResult) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
+
+type fakeRows struct {
+	res *fakeResult
+	pos int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.res.cols
+}
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string {
+
+	if index < len(r.res.types) {
+		return r.res.types[index]
+	}
+	return ""
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+
+	if r.pos >= len(r.res.rows) {
+		if r.res.rowsErr != nil {
+			return r.res.rowsErr
+		}
+		return io.EOF
+	}
+
+	copy(dest, r.res.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+// demoRowsResult rows of `test_table` for DemoRow
+func demoRowsResult(n int) *fakeResult {
+
+	res := &fakeResult{
+		cols: []string{"field_key", "field_one", "field_two", "field_thr", "field_fou"},
+	}
+	for i := 0; i < n; i++ {
+		res.rows = append(res.rows, []driver.Value{
+			"key" + string(rune('a'+i%26)), "one", i%2 == 0, int64(i), float64(i) / 2,
+		})
+	}
+
+	return res
+}
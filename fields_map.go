@@ -8,11 +8,47 @@ package sqlmapper
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"fmt"
+	"io"
+	"math"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Field db field
+// time.Time fields are bound as DATETIME, or as Unix epoch integer
+// with tag option `sql:"created,epoch=s"` / `sql:"created,epoch=ms"`:
+// a zero time.Time is bound as NULL and NULL is scanned by NullPolicy.
+// tag `sql:"#3"` maps a field to column index 3 (from 0) of a raw query
+// by SQLRawSelectByName / SQLRawSelectPartial, for views without stable
+// column names; such struct is not for generated statements.
+// int64 field with tag option `sql:"row_hash,hash"` holds a hash of
+// the other fields, filled before writes, see RowHashChanged.
+// field with tag option `sql:"total,expr='price * qty'"` is select-only:
+// SELECT has (price * qty) AS `total`, INSERT/UPDATE omit it.
+// field with tag option `sql:"id,pk"` is the primary key of *ByPriKey
+// methods, the first field if no field has it, fields tagged pk together
+// are a composite primary key bound in field order.
+// integer primary key with tag option `sql:"id,pk,auto"` is assigned by db
+// (AUTO_INCREMENT): INSERT omits it and sets it to LastInsertId.
+// integer field with tag option `sql:"version,version"` is an optimistic lock,
+// see ErrVersionConflict.
+// *time.Time field with tag option `sql:"deleted_at,softdelete"` marks rows
+// deleted by SQLDeleteByPriKey, see SetWithTrashed.
+// time.Time field with tag option `sql:"created_at,createtime"` is set on
+// insert, `sql:"updated_at,updatetime"` on insert and update, see SetClock.
+// field with tag option `sql:"ssn,vault"` is stored in a separate table
+// on another db, see SetVault.
+// fields of custom types implementing driver.Valuer (and sql.Scanner by
+// pointer), e.g. sql.NullString, UUID, JSON types, are bound & scanned by them.
+// pointer fields *int64, *string, *float64, *bool, *time.Time are for
+// nullable columns: nil is bound as NULL, NULL is scanned back as nil.
 // describe struct mapping in DB like:
 // type DemoRow struct {
 // 	FieldKey string  `sql:"field_key"`
@@ -28,17 +64,64 @@ type Field struct {
 	Type       string
 	Addr       interface{}
 	IntSave    sql.NullInt64
+	UintSave   sql.Null[uint64]
 	StringSave sql.NullString
 	FloatSave  sql.NullFloat64
 	BoolSave   sql.NullBool
+	TimeSave   sql.NullTime
+	enum       *enumTable
+	ptr        bool
+	epoch      string
+	ordinal    int
+	hash       bool
+	comment    string
+	coltype    string
+	expr       string
+	auto       bool
+	stamp      string
+	version    bool
+	soft       bool
 }
 
+// baseType type of Field without pointer, "*string" => "string",
+// narrower numbers are scanned as the wide type, "int32" => "int64"
+func baseType(typ string) string {
+
+	typ = strings.TrimPrefix(typ, "*")
+	switch typ {
+	case "int", "int8", "int16", "int32", "uint8", "uint16", "uint32":
+		return "int64"
+	case "uint":
+		return "uint64"
+	case "float32":
+		return "float64"
+	default:
+	}
+
+	return typ
+}
+
+// NullPolicy how a NULL column is mapped back to a non-pointer field
+type NullPolicy int
+
+const (
+	// LeaveOnNull field keeps its value, e.g. stale data of a reused struct
+	LeaveOnNull NullPolicy = iota
+	// ZeroOnNull field is set to its zero value (default)
+	ZeroOnNull
+	// ErrorOnNull scan fails with ErrUnexpectedNull
+	ErrorOnNull
+)
+
 // FieldsMap hold Field
 type FieldsMap interface {
 
 	// GetFields Fields
 	GetFields() []Field
 
+	// Rebind point to another object of the same struct type, without parsing it
+	Rebind(objptr interface{}) error
+
 	// GetFieldNamesInDB get Names in db from Fields
 	GetFieldNamesInDB() []string
 
@@ -48,6 +131,15 @@ type FieldsMap interface {
 	// GetFieldValue get Value in Object(struct)
 	GetFieldValue(idx int) interface{}
 
+	// GetPriKeyIndex index of (first) primary key field, by `pk` tag option or 0
+	GetPriKeyIndex() int
+
+	// GetPriKeyIndexes indexes of all primary key fields, more than one if composite
+	GetPriKeyIndexes() []int
+
+	// PrimaryKeyValue get Value of (first) primary key in Object(struct)
+	PrimaryKeyValue() interface{}
+
 	// GetFieldSaveAddrs get Pointers of Values in Object(struct)
 	GetFieldSaveAddrs() []interface{}
 
@@ -57,6 +149,57 @@ type FieldsMap interface {
 	// MapBackToObject mapping back to the original object
 	MapBackToObject() interface{}
 
+	// SetNullPolicy set how NULL is mapped back to Object(struct)
+	SetNullPolicy(policy NullPolicy)
+
+	// GetNullPolicy how NULL is mapped back to Object(struct)
+	GetNullPolicy() NullPolicy
+
+	// SetPriority set HIGH_PRIORITY / LOW_PRIORITY of generated statements
+	SetPriority(priority Priority)
+
+	// SetClock set time source of createtime / updatetime fields
+	SetClock(now func() time.Time)
+
+	// SetTimeLayout bind & scan time.Time as string formatted by layout
+	SetTimeLayout(layout string)
+
+	// Set set field nameInDB to value and mark it dirty for SQLUpdateByPriKey
+	Set(nameInDB string, value interface{}) error
+
+	// DirtyFields names in db of fields Set since loaded or updated
+	DirtyFields() []string
+
+	// ClearDirty forget dirty fields
+	ClearDirty()
+
+	// RowHashChanged values differ from field with `hash` tag option
+	RowHashChanged() bool
+
+	// SetScope scope all statements by column nameInDB = value, e.g. tenant_id
+	SetScope(nameInDB string, value interface{}) error
+
+	// SetSQLGuard set byte length & placeholder limits of statements
+	SetSQLGuard(guard SQLGuard)
+
+	// SetQueryCache cache rows of SELECT methods for ttl, 0 disables cache
+	SetQueryCache(ttl time.Duration)
+
+	// InvalidateQueryCache drop all rows cached
+	InvalidateQueryCache()
+
+	// View FieldsMap of the same Object(struct) on another table and dialect
+	View(table string, dialect Dialect) (FieldsMap, error)
+
+	// SetStmtCache reuse up to size statements prepared on db, 0 disables cache
+	SetStmtCache(size int)
+
+	// SetLogger log every statement with args, duration and error, nil by default
+	SetLogger(l Logger)
+
+	// StmtCacheStats hits, misses, evictions and size of statement cache
+	StmtCacheStats() StmtCacheStats
+
 	////////////////////////////////////////////////////////////////
 	// generate SQL string
 	// SQLFieldsStr generate sqlstr in db from Fields
@@ -65,6 +208,18 @@ type FieldsMap interface {
 	// SQLFieldsStrForSet generate sqlstr in db from Fields for set
 	SQLFieldsStrForSet() string
 
+	// SelectSQL SQL of SELECT with extStr, as SQLSelectStmt prepares
+	SelectSQL(extStr string) string
+
+	// InsertSQL SQL of INSERT, as SQLInsertStmt prepares
+	InsertSQL() string
+
+	// UpdateSQL SQL of UPDATE with extStr, as SQLUpdateStmt prepares
+	UpdateSQL(extStr string) string
+
+	// DeleteSQL SQL of DELETE with extStr, as SQLDeleteStmt prepares
+	DeleteSQL(extStr string) string
+
 	////////////////////////////////////////////////////////////////
 	// generate statement
 	// PrepareStmt prepare statement
@@ -89,11 +244,11 @@ type FieldsMap interface {
 
 	////////////////////////////////////////////////////////////////
 	// exec sql
-	// SQLLockByPriKey by primary key (field[0])
+	// SQLLockByPriKey by primary key, *NotFoundError (ErrNotFound) if no row
 	SQLLockByPriKey(ctx context.Context, tx *sql.Tx,
 		db *sql.DB) (interface{}, error)
 
-	// SQLSelectByPriKey by primary key (field[0])
+	// SQLSelectByPriKey by primary key, *NotFoundError (ErrNotFound) if no row
 	SQLSelectByPriKey(ctx context.Context, tx *sql.Tx,
 		db *sql.DB) (interface{}, error)
 
@@ -101,50 +256,395 @@ type FieldsMap interface {
 	SQLSelectRowsByFieldNameInDB(ctx context.Context, tx *sql.Tx,
 		db *sql.DB, nameInDB string) ([]interface{}, error)
 
+	// SQLSelectByFields select rows where every `sql` tag of criteria = its value
+	SQLSelectByFields(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		criteria map[string]interface{}) ([]interface{}, error)
+
+	// Where " where ..." extStr & args of cond, columns checked against `sql` tags
+	Where(cond *Condition) (string, []interface{}, error)
+
+	// SQLSelectWhere select rows matching cond
+	SQLSelectWhere(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		cond *Condition) ([]interface{}, error)
+
+	// SQLSelectByLike select rows of column LIKE pattern
+	SQLSelectByLike(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		nameInDB string, pattern string) ([]interface{}, error)
+
+	// SQLSelectBetween select rows of column BETWEEN low AND high
+	SQLSelectBetween(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		nameInDB string, low, high interface{}) ([]interface{}, error)
+
 	// SQLSelectAllRows
 	SQLSelectAllRows(ctx context.Context, tx *sql.Tx,
 		db *sql.DB) ([]interface{}, error)
 
+	// SQLSelect by condition in extStr, args bind to extStr
+	SQLSelect(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		extStr string, args ...interface{}) ([]interface{}, error)
+
+	// SQLSelectPage select limit rows after offset rows
+	SQLSelectPage(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		limit, offset int) ([]interface{}, error)
+
+	// OrderBy ORDER BY fragment of extStr, columns checked against `sql` tags
+	OrderBy(clauses []OrderClause) (string, error)
+
+	// SQLSelectAllRowsOrdered SQLSelectAllRows ordered by clauses
+	SQLSelectAllRowsOrdered(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		clauses []OrderClause) ([]interface{}, error)
+
+	// SQLSelectByPriKeys by primary keys,
+	// a temporary table is joined for keys more than SetKeysInThreshold
+	SQLSelectByPriKeys(ctx context.Context, tx *sql.Tx,
+		db *sql.DB, keys []interface{}) ([]interface{}, error)
+
+	// SQLSelectColumns select only cols of rows, other fields left zero
+	SQLSelectColumns(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		cols []string, extStr string, args ...interface{}) ([]interface{}, error)
+
+	// SQLSelectMapByPriKeys SQLSelectByPriKeys as map of primary key to row
+	SQLSelectMapByPriKeys(ctx context.Context, tx *sql.Tx,
+		db *sql.DB, keys []interface{}) (map[interface{}]interface{}, error)
+
+	// SQLDeleteByPriKeys delete rows of primary keys by one statement,
+	// return rows affected
+	SQLDeleteByPriKeys(ctx context.Context, tx *sql.Tx,
+		db *sql.DB, keys []interface{}) (int64, error)
+
+	// SQLUpdateFieldsByPriKeys set cols to values of Object(struct) on rows of keys,
+	// return rows affected
+	SQLUpdateFieldsByPriKeys(ctx context.Context, tx *sql.Tx,
+		db *sql.DB, cols []string, keys []interface{}) (int64, error)
+
+	// SetKeysInThreshold set keys count to switch SQLSelectByPriKeys to temporary table
+	SetKeysInThreshold(n int)
+
 	// SQLInsert
 	SQLInsert(ctx context.Context, tx *sql.Tx, db *sql.DB) error
 
-	// SQLUpdateByPriKey by primary key (field[0])
+	// SQLInsertResult SQLInsert returning sql.Result, for LastInsertId
+	SQLInsertResult(ctx context.Context, tx *sql.Tx, db *sql.DB) (sql.Result, error)
+
+	// SQLInsertValues insert one row from values in field order, without Object(struct)
+	SQLInsertValues(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		values []interface{}) error
+
+	// SQLInsertReturning insert, scan returned row back into Object(struct)
+	SQLInsertReturning(ctx context.Context, tx *sql.Tx, db *sql.DB) error
+
+	// SQLUpdateByPriKey by primary key
 	SQLUpdateByPriKey(ctx context.Context, tx *sql.Tx, db *sql.DB) error
 
-	// SQLDeleteByPriKey by primary key (field[0])
+	// SQLUpdateResult SQLUpdateByPriKey returning sql.Result, for RowsAffected
+	SQLUpdateResult(ctx context.Context, tx *sql.Tx, db *sql.DB) (sql.Result, error)
+
+	// SetVault route fields with `vault` tag option to table on db
+	SetVault(table string, db *sql.DB) error
+
+	// SetStrictUpdate SQLUpdateByPriKey return ErrNoRowsAffected if no row updated
+	SetStrictUpdate(on bool)
+
+	// SQLDeleteByPriKey by primary key
 	SQLDeleteByPriKey(ctx context.Context, tx *sql.Tx, db *sql.DB) error
+
+	// SQLIncrementByPriKey add delta to a column of the row of primary key atomically
+	SQLIncrementByPriKey(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		nameInDB string, delta int64) (int64, error)
+
+	// SQLForceDeleteByPriKey DELETE by primary key even with softdelete field
+	SQLForceDeleteByPriKey(ctx context.Context, tx *sql.Tx, db *sql.DB) error
+
+	// SetWithTrashed statements see soft deleted rows too
+	SetWithTrashed(on bool)
+
+	// SQLDeleteResult SQLDeleteByPriKey returning sql.Result, for RowsAffected
+	SQLDeleteResult(ctx context.Context, tx *sql.Tx, db *sql.DB) (sql.Result, error)
+
+	// SQLInsertBatch insert objects with one multi-row INSERT
+	SQLInsertBatch(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		objptrs []interface{}) error
+
+	// SetBatchFallback insert rows one by one on failed SQLInsertBatch to report each row
+	SetBatchFallback(on bool)
+
+	// SetBatchOrder insert rows of SQLInsertBatch in order of less, e.g. for foreign keys
+	SetBatchOrder(less func(a, b interface{}) bool)
+
+	// SQLSyncByParentKey replace rows where parentField = parentValue with objptrs
+	SQLSyncByParentKey(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		parentField string, parentValue interface{}, objptrs []interface{}) error
+
+	// SQLUpsert insert, or update updateCols on duplicate key,
+	// all fields except primary key if no updateCols
+	SQLUpsert(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		updateCols ...string) error
+
+	// SQLUpdateManyByPriKey update objects by primary key
+	// with one prepared statement, return total rows affected
+	SQLUpdateManyByPriKey(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		objptrs []interface{}) (int64, error)
+
+	// SQLUpdateByCond by condition in extStr, args bind to extStr
+	SQLUpdateByCond(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		extStr string, args ...interface{}) error
+
+	// SQLUpdateByCondReturningKeys by condition in extStr,
+	// return primary keys of updated rows
+	SQLUpdateByCondReturningKeys(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		extStr string, args ...interface{}) ([]interface{}, error)
+
+	// SQLRawSelect run a raw query, columns map to fields by position
+	SQLRawSelect(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		sqlstr string, args ...interface{}) ([]interface{}, error)
+
+	// SQLRawSelectByName run a raw query, columns map to fields by `sql` tag,
+	// columns not in fields are ignored
+	SQLRawSelectByName(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		sqlstr string, args ...interface{}) ([]interface{}, error)
+
+	// SQLRawSelectPartial run a raw query, columns map to fields by `sql` tag,
+	// fields without column are left zero, return rows and tags loaded
+	SQLRawSelectPartial(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		sqlstr string, args ...interface{}) ([]interface{}, []string, error)
+
+	// SQLSelectRandom select n random rows, extStr is an optional WHERE
+	SQLSelectRandom(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		n int, extStr string, args ...interface{}) ([]interface{}, error)
+
+	// SQLCount count rows matching extStr
+	SQLCount(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		extStr string, args ...interface{}) (int64, error)
+
+	// SQLCountAll count all rows in table
+	SQLCountAll(ctx context.Context, tx *sql.Tx, db *sql.DB) (int64, error)
+
+	// SQLExistsByPriKey row of primary key exists
+	SQLExistsByPriKey(ctx context.Context, tx *sql.Tx, db *sql.DB) (bool, error)
+
+	// SQLCountDistinct count distinct tuples of fields by `sql` tags
+	SQLCountDistinct(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		namesInDB []string, extStr string, args ...interface{}) (int64, error)
+
+	// SetPluckNull set how SQLPluck handles NULL
+	SetPluckNull(opt PluckNull)
+
+	// SQLPluck select values of one field by `sql` tag,
+	// extStr & args for WHERE etc.
+	SQLPluck(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		nameInDB string, extStr string, args ...interface{}) ([]interface{}, error)
+
+	// SQLSelectGroupBy select rows grouped by value of field with `sql` tag
+	SQLSelectGroupBy(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		nameInDB string, extStr string, args ...interface{}) (map[interface{}][]interface{}, error)
+
+	// SQLSelectEach call fn for each row of one query, without collecting rows
+	SQLSelectEach(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		extStr string, fn func(obj interface{}) error, args ...interface{}) error
+
+	// SQLForEachRow call fn for each row in table, rows are fetched
+	// chunkSize at a time by primary key order
+	SQLForEachRow(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		chunkSize int, fn func(obj interface{}) error) error
+
+	////////////////////////////////////////////////////////////////
+	// schema
+	// SQLAlterTableAddMissing add columns of fields missing in table,
+	// return ALTER statements executed, columns are never dropped
+	SQLAlterTableAddMissing(ctx context.Context, tx *sql.Tx,
+		db *sql.DB) ([]string, error)
+
+	// CreateTableSQL CREATE TABLE IF NOT EXISTS of fields & primary key
+	CreateTableSQL() string
+
+	// SQLCreateTable exec CreateTableSQL
+	SQLCreateTable(ctx context.Context, tx *sql.Tx, db *sql.DB) error
+
+	// SQLValidateSchema check columns & types of table, *SchemaError
+	// with a suggested ALTER for each mismatch, read-only
+	SQLValidateSchema(ctx context.Context, tx *sql.Tx, db *sql.DB) error
+
+	////////////////////////////////////////////////////////////////
+	// csv
+	// SQLExportCSV stream rows selected by extStr to w as CSV
+	SQLExportCSV(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		extStr string, w io.Writer, args ...interface{}) error
+
+	// SQLImportCSV insert rows read from CSV r, header are `sql` tags
+	SQLImportCSV(ctx context.Context, tx *sql.Tx, db *sql.DB,
+		r io.Reader) (int64, error)
+
+	////////////////////////////////////////////////////////////////
+	// exec sql on Executor (*sql.DB, *sql.Tx or *sql.Conn)
+	// SQLSelectByPriKeyOn SQLSelectByPriKey on exec
+	SQLSelectByPriKeyOn(ctx context.Context, exec Executor) (interface{}, error)
+
+	// SQLSelectAllRowsOn SQLSelectAllRows on exec
+	SQLSelectAllRowsOn(ctx context.Context, exec Executor) ([]interface{}, error)
+
+	// SQLInsertOn SQLInsert on exec
+	SQLInsertOn(ctx context.Context, exec Executor) error
+
+	// SQLUpdateByPriKeyOn SQLUpdateByPriKey on exec
+	SQLUpdateByPriKeyOn(ctx context.Context, exec Executor) error
+
+	// SQLDeleteByPriKeyOn SQLDeleteByPriKey on exec
+	SQLDeleteByPriKeyOn(ctx context.Context, exec Executor) error
+
+	////////////////////////////////////////////////////////////////
+	// exec sql with default context
+	// SetDefaultContext set context used by *DefaultCtx methods
+	SetDefaultContext(ctx context.Context)
+
+	// DefaultContext context set by SetDefaultContext,
+	// ErrNoDefaultContext if not set
+	DefaultContext() (context.Context, error)
+
+	// SQLLockByPriKeyDefaultCtx SQLLockByPriKey with default context
+	SQLLockByPriKeyDefaultCtx(tx *sql.Tx, db *sql.DB) (interface{}, error)
+
+	// SQLSelectByPriKeyDefaultCtx SQLSelectByPriKey with default context
+	SQLSelectByPriKeyDefaultCtx(tx *sql.Tx, db *sql.DB) (interface{}, error)
+
+	// SQLSelectRowsByFieldNameInDBDefaultCtx SQLSelectRowsByFieldNameInDB
+	// with default context
+	SQLSelectRowsByFieldNameInDBDefaultCtx(tx *sql.Tx, db *sql.DB,
+		nameInDB string) ([]interface{}, error)
+
+	// SQLSelectAllRowsDefaultCtx SQLSelectAllRows with default context
+	SQLSelectAllRowsDefaultCtx(tx *sql.Tx, db *sql.DB) ([]interface{}, error)
+
+	// SQLInsertDefaultCtx SQLInsert with default context
+	SQLInsertDefaultCtx(tx *sql.Tx, db *sql.DB) error
+
+	// SQLUpdateByPriKeyDefaultCtx SQLUpdateByPriKey with default context
+	SQLUpdateByPriKeyDefaultCtx(tx *sql.Tx, db *sql.DB) error
+
+	// SQLDeleteByPriKeyDefaultCtx SQLDeleteByPriKey with default context
+	SQLDeleteByPriKeyDefaultCtx(tx *sql.Tx, db *sql.DB) error
+
+	// SQLUpdateByCondDefaultCtx SQLUpdateByCond with default context
+	SQLUpdateByCondDefaultCtx(tx *sql.Tx, db *sql.DB,
+		extStr string, args ...interface{}) error
 }
 
 ////////////////////////////////////////////////////////////////
 
-// NewFieldsMap new Fields
+// NewFieldsMap new Fields,
+// struct is parsed once per type, later calls only bind field addresses,
+// column of a field without `sql` tag is its name in snake_case (FieldKey => field_key),
+// unexported fields and fields tagged `sql:"-"` have no column and are not mapped,
+// fields of untagged embedded structs (not pointers) are mapped as fields of the struct,
+// table is a name of letters, digits & underscores, optionally schema prefixed
 func NewFieldsMap(table string, objptr interface{}) (FieldsMap, error) {
 
-	elem := reflect.ValueOf(objptr).Elem()
-	reftype := elem.Type()
+	return NewFieldsMapWithTagKeys(table, objptr, defaultTagKey)
+}
+
+// NewFieldsMapWithTagKey NewFieldsMap with columns & options read from
+// tagKey tags instead of `sql`, empty tagKey is `sql`,
+// rows scanned by the FieldsMap use the same tag key
+// example: fm, err := NewFieldsMapWithTagKey("test_table", &row, "db")
+func NewFieldsMapWithTagKey(table string, objptr interface{},
+	tagKey string) (FieldsMap, error) {
+
+	return NewFieldsMapWithTagKeys(table, objptr, tagKey)
+}
+
+// NewFieldsMapWithTagKeys NewFieldsMap with each field's column & options
+// read from the first of tagKeys tags it has (not empty),
+// snake_case field name if none, no tagKeys is `sql`
+// example: fm, err := NewFieldsMapWithTagKeys("test_table", &row, "sql", "db", "json")
+func NewFieldsMapWithTagKeys(table string, objptr interface{},
+	tagKeys ...string) (FieldsMap, error) {
 
-	var fields []Field
-	for i, flen := 0, reftype.NumField(); i < flen; i++ {
+	if err := checkTable(table); err != nil {
+		return nil, err
+	}
 
-		var field Field
-		field.Type = reftype.Field(i).Type.String()
-		if field.Type != "int64" && field.Type != "string" &&
-			field.Type != "float64" && field.Type != "bool" {
-			return nil, errors.New("Unsupported Type: " + field.Type)
+	keys := make([]string, 0, len(tagKeys))
+	for i, klen := 0, len(tagKeys); i < klen; i++ {
+		if len(tagKeys[i]) > 0 {
+			keys = append(keys, tagKeys[i])
 		}
+	}
+	if len(keys) == 0 {
+		keys = append(keys, defaultTagKey)
+	}
+
+	layout, err := cachedTagLayout(reflect.ValueOf(objptr).Elem().Type(), keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFieldsMapFromLayout(table, objptr, layout), nil
+}
+
+// tableRe table name, optionally prefixed by schema
+var tableRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// checkTable error if table is not a plain (schema.)name,
+// table is part of every statement and can not be bound
+func checkTable(table string) error {
+
+	if !tableRe.MatchString(table) {
+		return fmt.Errorf("invalid table name %q", table)
+	}
+
+	return nil
+}
+
+// newFieldsMapFromLayout bind objptr to a parsed layout, no reflect walk
+func newFieldsMapFromLayout(table string, objptr interface{},
+	layout *structLayout) *_FieldsMap {
 
-		field.Name = reftype.Field(i).Name
-		field.Tag = reftype.Field(i).Tag.Get("sql")
-		field.Addr = elem.Field(i).Addr().Interface()
-		fields = append(fields, field)
+	elem := reflect.ValueOf(objptr).Elem()
+
+	fields := make([]Field, len(layout.fields))
+	for i, flen := 0, len(layout.fields); i < flen; i++ {
+		fields[i] = newField(elem, &layout.fields[i])
 	}
 
-	return &_FieldsMap{
+	fds := &_FieldsMap{
 		objptr:  objptr,
-		reftype: reftype,
+		reftype: layout.reftype,
+		tagKeys: layout.tagKeys,
 		fields:  fields,
 		table:   table,
-	}, nil
+		dialect: MySQL,
+		pk:      layout.pks[0],
+		pks:     layout.pks,
+
+		nullPolicy: ZeroOnNull,
+	}
+	if len(layout.vault) > 0 {
+		fds.vault = newVaultMap(fds, elem, layout)
+	}
+
+	return fds
+}
+
+// newField Field of struct field in elem described by lf
+func newField(elem reflect.Value, lf *fieldLayout) Field {
+
+	return Field{
+		Name:    lf.name,
+		Tag:     lf.tag,
+		Type:    lf.typ,
+		enum:    lf.enum,
+		ptr:     lf.ptr,
+		epoch:   lf.epoch,
+		ordinal: lf.ordinal,
+		hash:    lf.hash,
+		comment: lf.comment,
+		coltype: lf.coltype,
+		expr:    lf.expr,
+		auto:    lf.auto,
+		stamp:   lf.stamp,
+		version: lf.version,
+		soft:    lf.soft,
+		Addr:    elem.FieldByIndex(lf.index).Addr().Interface(),
+	}
 }
 
 ////////////////////////////////////////////////////////////////
@@ -154,8 +654,32 @@ var _ FieldsMap = &_FieldsMap{}
 type _FieldsMap struct {
 	objptr  interface{}
 	reftype reflect.Type
+	tagKeys []string // struct tag keys of columns, by priority
 	fields  []Field
 	table   string
+	dialect Dialect
+	pk      int             // index of (first) primary key field
+	pks     []int           // indexes of primary key fields
+	ctx     context.Context // default context
+
+	nullPolicy      NullPolicy
+	pluckNull       PluckNull
+	priority        Priority
+	timeLayout      string
+	keysInThreshold int
+	scope           *scope
+	batchFallback   bool
+	batchLess       func(a, b interface{}) bool
+	guard           *SQLGuard
+	cache           *queryCache
+	stmts           *stmtCache
+	logger          Logger
+	dirty           []bool // fields Set, nil if none
+	strictUpdate    bool
+	now             func() time.Time // clock of createtime / updatetime / softdelete fields
+	withTrashed     bool
+	vault           *_FieldsMap // primary key & `vault` fields, see SetVault
+	vaultDB         *sql.DB
 }
 
 // GetFields get Fields for an Object(struct)
@@ -186,35 +710,175 @@ func (fds *_FieldsMap) GetFieldNamesInDB() []string {
 	return tags
 }
 
-// GetFieldValues get Values in Object(struct)
+// GetFieldValues get Values in Object(struct) to bind,
+// select-only (expr) fields are omitted, like SQLFieldsStr
 func (fds *_FieldsMap) GetFieldValues() []interface{} {
 
 	var values []interface{}
 	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if len(fds.fields[i].expr) > 0 {
+			continue
+		}
 		values = append(values, fds.GetFieldValue(i))
 	}
 
 	return values
 }
 
-// GetFieldValue get Values in Object(struct)
+// GetFieldValue get Values in Object(struct),
+// nil for a nil pointer field, so NULL is bound
 func (fds *_FieldsMap) GetFieldValue(idx int) interface{} {
 
+	if fds.fields[idx].ptr {
+		v := reflect.ValueOf(fds.fields[idx].Addr).Elem()
+		if v.IsNil() {
+			return nil
+		}
+		return fds.bindValue(idx, v.Elem().Interface())
+	}
+
 	switch fds.fields[idx].Type {
 	case "int64":
 		return *fds.fields[idx].Addr.(*int64)
+	case "uint64":
+		return fds.bindValue(idx, *fds.fields[idx].Addr.(*uint64))
 	case "string":
 		return *fds.fields[idx].Addr.(*string)
 	case "float64":
 		return *fds.fields[idx].Addr.(*float64)
 	case "bool":
 		return *fds.fields[idx].Addr.(*bool)
+	case "enum":
+		return reflect.ValueOf(fds.fields[idx].Addr).Elem().Int()
+	case "scanner":
+		return reflect.ValueOf(fds.fields[idx].Addr).Elem().Interface()
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32", "float32":
+		return fds.bindValue(idx, reflect.ValueOf(fds.fields[idx].Addr).Elem().Interface())
+	case "time.Time":
+		return fds.bindValue(idx, *fds.fields[idx].Addr.(*time.Time))
+	default:
+	}
+
+	return nil
+}
+
+// bindValue convert Go value v of field idx to bind,
+// v is uint64 / time.Time of the field, narrower numbers are widened
+// to int64 / float64, other types are returned as is
+func (fds *_FieldsMap) bindValue(idx int, v interface{}) interface{} {
+
+	switch v := v.(type) {
+	case int, int8, int16, int32:
+		return reflect.ValueOf(v).Int()
+	case uint8, uint16, uint32:
+		return int64(reflect.ValueOf(v).Uint())
+	case uint:
+		return fds.bindValue(idx, uint64(v))
+	case float32:
+		return float64(v)
+	case uint64:
+		// database/sql can not bind uint64 with high bit set
+		if v > math.MaxInt64 {
+			return strconv.FormatUint(v, 10)
+		}
+		return v
+	case time.Time:
+		switch fds.fields[idx].epoch {
+		case "s":
+			if v.IsZero() {
+				return nil
+			}
+			return v.Unix()
+		case "ms":
+			if v.IsZero() {
+				return nil
+			}
+			return v.UnixMilli()
+		default:
+		}
+		if fds.timeAsString(idx) {
+			return fds.formatTime(v)
+		}
+		return v
 	default:
 	}
 
+	return v
+}
+
+// GetPriKeyIndex index of primary key field in Fields,
+// the field with tag option `sql:"id,pk"`, or 0 (first field) if none,
+// the first of them for a composite primary key
+func (fds *_FieldsMap) GetPriKeyIndex() int {
+
+	return fds.pk
+}
+
+// GetPriKeyIndexes indexes of primary key fields in Fields, in field order
+func (fds *_FieldsMap) GetPriKeyIndexes() []int {
+
+	return append([]int(nil), fds.pks...)
+}
+
+// isPriKey field idx is (part of) primary key
+func (fds *_FieldsMap) isPriKey(idx int) bool {
+
+	for i, plen := 0, len(fds.pks); i < plen; i++ {
+		if fds.pks[i] == idx {
+			return true
+		}
+	}
+
+	return false
+}
+
+// priKeyCond condition on primary key fields, AND of each for composite key
+// example:"`tenant_id` = ? AND `user_id` = ?"
+func (fds *_FieldsMap) priKeyCond() string {
+
+	var cond string
+	for i, plen := 0, len(fds.pks); i < plen; i++ {
+		if i > 0 {
+			cond += " AND "
+		}
+		cond += fds.quote(fds.fields[fds.pks[i]].Tag) + " = ?"
+	}
+
+	return cond
+}
+
+// priKeyValues values of primary key fields bound to priKeyCond
+func (fds *_FieldsMap) priKeyValues() []interface{} {
+
+	values := make([]interface{}, 0, len(fds.pks))
+	for i, plen := 0, len(fds.pks); i < plen; i++ {
+		values = append(values, fds.GetFieldValue(fds.pks[i]))
+	}
+
+	return values
+}
+
+// singlePriKey error for methods taking one key value on composite primary key
+func (fds *_FieldsMap) singlePriKey() error {
+
+	if len(fds.pks) > 1 {
+		return errors.New("composite primary key not supported: " + fds.table)
+	}
+
 	return nil
 }
 
+// PrimaryKeyValue get Value of primary key in Object(struct),
+// the first of them for a composite primary key, nil if no field
+func (fds *_FieldsMap) PrimaryKeyValue() interface{} {
+
+	if len(fds.fields) == 0 {
+		return nil
+	}
+
+	return fds.GetFieldValue(fds.pk)
+}
+
 // GetFieldSaveAddrs get Pointers of Values in Object(struct)
 func (fds *_FieldsMap) GetFieldSaveAddrs() []interface{} {
 
@@ -229,15 +893,30 @@ func (fds *_FieldsMap) GetFieldSaveAddrs() []interface{} {
 // GetFieldSaveAddr get Pointers of Values in Object(struct)
 func (fds *_FieldsMap) GetFieldSaveAddr(idx int) interface{} {
 
-	switch fds.fields[idx].Type {
+	switch baseType(fds.fields[idx].Type) {
 	case "int64":
 		return &fds.fields[idx].IntSave
+	case "uint64":
+		return &fds.fields[idx].UintSave
 	case "string":
 		return &fds.fields[idx].StringSave
 	case "float64":
 		return &fds.fields[idx].FloatSave
 	case "bool":
 		return &fds.fields[idx].BoolSave
+	case "enum":
+		return &fds.fields[idx].IntSave
+	case "scanner":
+		// scanned into Object(struct) directly
+		return fds.fields[idx].Addr
+	case "time.Time":
+		if len(fds.fields[idx].epoch) > 0 {
+			return &fds.fields[idx].IntSave
+		}
+		if fds.timeAsString(idx) {
+			return &fds.fields[idx].StringSave
+		}
+		return &fds.fields[idx].TimeSave
 	default:
 	}
 
@@ -247,73 +926,399 @@ func (fds *_FieldsMap) GetFieldSaveAddr(idx int) interface{} {
 // MapBackToObject mapping back to the original object
 func (fds *_FieldsMap) MapBackToObject() interface{} {
 
+	objptr, _ := fds.mapBack()
+	return objptr
+}
+
+// mapBack mapping back to the original object,
+// NULL values are handled by NullPolicy,
+// error if a scanned value is invalid for its field
+func (fds *_FieldsMap) mapBack() (interface{}, error) {
+
+	var err error
 	for i, flen := 0, len(fds.fields); i < flen; i++ {
-		switch fds.fields[i].Type {
-		case "int64":
-			if fds.fields[i].IntSave.Valid {
-				*fds.fields[i].Addr.(*int64) = fds.fields[i].IntSave.Int64
-			}
+		if e := fds.mapBackField(i); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return fds.objptr, err
+}
+
+// mapBackField mapping back one Field to the original object,
+// a pointer field is set nil on NULL, else to a new value
+func (fds *_FieldsMap) mapBackField(idx int) error {
+
+	addr := fds.fields[idx].Addr
+	var ptr, nv reflect.Value
+	if fds.fields[idx].ptr {
+		ptr = reflect.ValueOf(addr).Elem()
+		if !fds.saveValid(idx) {
+			ptr.Set(reflect.Zero(ptr.Type()))
+			return nil
+		}
+		nv = reflect.New(ptr.Type().Elem())
+		addr = nv.Interface()
+	}
+
+	if !fds.saveValid(idx) {
+		switch fds.nullPolicy {
+		case ZeroOnNull:
+			v := reflect.ValueOf(fds.fields[idx].Addr).Elem()
+			v.Set(reflect.Zero(v.Type()))
 			break
-		case "string":
-			if fds.fields[i].StringSave.Valid {
-				*fds.fields[i].Addr.(*string) = fds.fields[i].StringSave.String
-			}
+		case ErrorOnNull:
+			return fmt.Errorf("%w: `%s`", ErrUnexpectedNull, fds.fields[idx].Tag)
+		default:
+		}
+		return nil
+	}
+
+	switch baseType(fds.fields[idx].Type) {
+	case "int64":
+		if p, ok := addr.(*int64); ok {
+			*p = fds.fields[idx].IntSave.Int64
 			break
-		case "float64":
-			if fds.fields[i].FloatSave.Valid {
-				*fds.fields[i].Addr.(*float64) = fds.fields[i].FloatSave.Float64
-			}
+		}
+		if err := setNarrowInt(addr, fds.fields[idx].IntSave.Int64); err != nil {
+			return fmt.Errorf("`%s`: %w", fds.fields[idx].Tag, err)
+		}
+		break
+	case "uint64":
+		if p, ok := addr.(*uint64); ok {
+			*p = fds.fields[idx].UintSave.V
 			break
-		case "bool":
-			if fds.fields[i].BoolSave.Valid {
-				*fds.fields[i].Addr.(*bool) = fds.fields[i].BoolSave.Bool
-			}
+		}
+		v := reflect.ValueOf(addr).Elem()
+		if v.OverflowUint(fds.fields[idx].UintSave.V) {
+			return fmt.Errorf("`%s`: %d overflows %s", fds.fields[idx].Tag,
+				fds.fields[idx].UintSave.V, v.Type())
+		}
+		v.SetUint(fds.fields[idx].UintSave.V)
+		break
+	case "string":
+		*addr.(*string) = fds.fields[idx].StringSave.String
+		break
+	case "float64":
+		if p, ok := addr.(*float64); ok {
+			*p = fds.fields[idx].FloatSave.Float64
+			break
+		}
+		v := reflect.ValueOf(addr).Elem()
+		if v.OverflowFloat(fds.fields[idx].FloatSave.Float64) {
+			return fmt.Errorf("`%s`: %g overflows %s", fds.fields[idx].Tag,
+				fds.fields[idx].FloatSave.Float64, v.Type())
+		}
+		v.SetFloat(fds.fields[idx].FloatSave.Float64)
+		break
+	case "bool":
+		*addr.(*bool) = fds.fields[idx].BoolSave.Bool
+		break
+	case "enum":
+		err := fds.fields[idx].enum.check(fds.fields[idx].IntSave.Int64)
+		if err != nil {
+			return err
+		}
+		reflect.ValueOf(addr).Elem().SetInt(fds.fields[idx].IntSave.Int64)
+		break
+	case "time.Time":
+		switch fds.fields[idx].epoch {
+		case "s":
+			*addr.(*time.Time) = time.Unix(fds.fields[idx].IntSave.Int64, 0).UTC()
+			break
+		case "ms":
+			*addr.(*time.Time) = time.UnixMilli(fds.fields[idx].IntSave.Int64).UTC()
 			break
 		default:
+			if fds.timeAsString(idx) {
+				t, err := fds.parseTime(fds.fields[idx].StringSave.String)
+				if err != nil {
+					return fmt.Errorf("`%s`: %w", fds.fields[idx].Tag, err)
+				}
+				*addr.(*time.Time) = t
+				break
+			}
+			*addr.(*time.Time) = fds.fields[idx].TimeSave.Time
 		}
+		break
+	default:
+	}
+	if ptr.IsValid() {
+		ptr.Set(nv)
 	}
 
-	return fds.objptr
+	return nil
 }
 
-////////////////////////////////////////////////////////////////
-// generate SQL string
-
-// SQLFieldsStr generate sqlstr in db from Fields
-// example:" `field0`, `field1`, `field2`, `field3` "
-func (fds *_FieldsMap) SQLFieldsStr() string {
+// setNarrowInt set n to the int / uint field at addr, e.g. LastInsertId to an auto key,
+// error if n is out of range of the field
+func setNarrowInt(addr interface{}, n int64) error {
 
-	var tagsStr string
-	for i, flen := 0, len(fds.fields); i < flen; i++ {
-		if len(tagsStr) > 0 {
-			tagsStr += ", "
+	v := reflect.ValueOf(addr).Elem()
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n < 0 || v.OverflowUint(uint64(n)) {
+			return fmt.Errorf("%d overflows %s", n, v.Type())
 		}
-		tagsStr += "`"
-		tagsStr += fds.fields[i].Tag
-		tagsStr += "`"
-	}
-	if len(tagsStr) > 0 {
-		tagsStr += " "
-		tagsStr = " " + tagsStr
+		v.SetUint(uint64(n))
+	default:
+		if v.OverflowInt(n) {
+			return fmt.Errorf("%d overflows %s", n, v.Type())
+		}
+		v.SetInt(n)
 	}
 
-	return tagsStr
+	return nil
 }
 
-// SQLFieldsStrForSet generate sqlstr in db from Fields for set
-// example:" `field0` = ?, `field1` = ?, `field2` = ?, `field3` = ? "
-func (fds *_FieldsMap) SQLFieldsStrForSet() string {
+// saveValid scanned value of Field is not NULL
+func (fds *_FieldsMap) saveValid(idx int) bool {
 
-	var tagsStr string
-	for i, flen := 0, len(fds.fields); i < flen; i++ {
-		if len(tagsStr) > 0 {
-			tagsStr += ", "
+	switch baseType(fds.fields[idx].Type) {
+	case "int64", "enum":
+		return fds.fields[idx].IntSave.Valid
+	case "uint64":
+		return fds.fields[idx].UintSave.Valid
+	case "string":
+		return fds.fields[idx].StringSave.Valid
+	case "float64":
+		return fds.fields[idx].FloatSave.Valid
+	case "bool":
+		return fds.fields[idx].BoolSave.Valid
+	case "scanner":
+		// NULL is handled by Scan of the type
+		return true
+	case "time.Time":
+		if len(fds.fields[idx].epoch) > 0 {
+			return fds.fields[idx].IntSave.Valid
 		}
-		tagsStr += "`"
-		tagsStr += fds.fields[i].Tag
-		tagsStr += "`"
-		tagsStr += " = ?"
-	}
+		if fds.timeAsString(idx) {
+			return fds.fields[idx].StringSave.Valid
+		}
+		return fds.fields[idx].TimeSave.Valid
+	default:
+	}
+
+	return false
+}
+
+// SetNullPolicy set how NULL is mapped back to non-pointer fields
+// of Object(struct), ZeroOnNull by default
+// example: fds.SetNullPolicy(sqlmapper.LeaveOnNull)
+func (fds *_FieldsMap) SetNullPolicy(policy NullPolicy) {
+
+	fds.nullPolicy = policy
+	if fds.vault != nil {
+		fds.vault.nullPolicy = policy
+	}
+}
+
+// GetNullPolicy how NULL is mapped back to Object(struct)
+func (fds *_FieldsMap) GetNullPolicy() NullPolicy {
+
+	return fds.nullPolicy
+}
+
+// checkValues check values in Object(struct) before bind,
+// then fill scope & row hash field
+func (fds *_FieldsMap) checkValues() error {
+
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if fds.fields[i].Type == "enum" {
+			err := fds.fields[i].enum.check(fds.GetFieldValue(i).(int64))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	fds.fillScope()
+	fds.fillRowHash()
+
+	return nil
+}
+
+// fieldGoValue value of field idx in Object(struct) as its Go type,
+// unlike GetFieldValue it is not converted for binding
+func (fds *_FieldsMap) fieldGoValue(idx int) interface{} {
+
+	return reflect.ValueOf(fds.fields[idx].Addr).Elem().Interface()
+}
+
+// eqCond condition of column nameInDB equal to value with its args,
+// "`c` IS NULL" without arg for NULL value, as "`c` = NULL" never matches
+func (fds *_FieldsMap) eqCond(nameInDB string, value interface{}) (string, []interface{}) {
+
+	if isNullValue(value) {
+		return fds.quote(nameInDB) + " IS NULL", nil
+	}
+
+	return fds.quote(nameInDB) + " = ?", []interface{}{value}
+}
+
+// isNullValue value is bound as NULL:
+// nil, nil pointer or driver.Valuer of nil (sql.NullString{} ...)
+func isNullValue(value interface{}) bool {
+
+	if value == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return true
+	}
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		return err == nil && v == nil
+	}
+
+	return false
+}
+
+// fieldIndex index of field by `sql` tag, -1 if no field match
+func (fds *_FieldsMap) fieldIndex(nameInDB string) int {
+
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if fds.fields[i].Tag == nameInDB {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// sameTypeRowMap newRowMap for objptr of the same struct type as fds
+func (fds *_FieldsMap) sameTypeRowMap(objptr interface{}) (*_FieldsMap, error) {
+
+	reftype := reflect.TypeOf(objptr)
+	if reftype == nil || reftype.Kind() != reflect.Ptr ||
+		reftype.Elem() != fds.reftype {
+		return nil, fmt.Errorf("object %T is not *%s", objptr, fds.reftype.String())
+	}
+
+	return fds.newRowMap(objptr)
+}
+
+// Rebind point fds to objptr, a new object of the same struct type,
+// Addr of fields are rebound without parsing the struct again,
+// options are kept, dirty fields are cleared
+// example: fds.Rebind(&DemoRow{})
+func (fds *_FieldsMap) Rebind(objptr interface{}) error {
+
+	ov := reflect.ValueOf(objptr)
+	if !ov.IsValid() || ov.Type() != reflect.PointerTo(fds.reftype) || ov.IsNil() {
+		return fmt.Errorf("Rebind needs non nil *%s, got %T", fds.reftype.String(), objptr)
+	}
+
+	layout, err := cachedTagLayout(fds.reftype, fds.tagKeys)
+	if err != nil {
+		return err
+	}
+
+	elem := ov.Elem()
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		fds.fields[i].Addr = elem.FieldByIndex(layout.fields[i].index).Addr().Interface()
+	}
+	fds.objptr = objptr
+	fds.dirty = nil
+
+	if fds.vault != nil {
+		vault := newVaultMap(fds, elem, layout)
+		for i, vlen := 0, len(vault.fields); i < vlen; i++ {
+			fds.vault.fields[i].Addr = vault.fields[i].Addr
+		}
+		fds.vault.objptr = objptr
+	}
+
+	return nil
+}
+
+// newRowMap new FieldsMap for a row scanned by fds, options of fds are kept
+func (fds *_FieldsMap) newRowMap(objptr interface{}) (*_FieldsMap, error) {
+
+	fieldsMap, err := NewFieldsMapWithTagKeys(fds.table, objptr, fds.tagKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	rowMap := fieldsMap.(*_FieldsMap)
+	rowMap.nullPolicy = fds.nullPolicy
+	rowMap.priority = fds.priority
+	rowMap.timeLayout = fds.timeLayout
+	rowMap.scope = fds.scope
+	rowMap.guard = fds.guard
+	rowMap.dialect = fds.dialect
+	rowMap.now = fds.now
+	rowMap.withTrashed = fds.withTrashed
+	rowMap.logger = fds.logger
+	return rowMap, nil
+}
+
+////////////////////////////////////////////////////////////////
+// generate SQL string
+
+// SQLFieldsStr generate sqlstr in db from Fields,
+// select-only (expr) fields are omitted
+// example:" `field0`, `field1`, `field2`, `field3` "
+func (fds *_FieldsMap) SQLFieldsStr() string {
+
+	var tagsStr string
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if len(fds.fields[i].expr) > 0 {
+			continue
+		}
+		if len(tagsStr) > 0 {
+			tagsStr += ", "
+		}
+		tagsStr += fds.quote(fds.fields[i].Tag)
+	}
+	if len(tagsStr) > 0 {
+		tagsStr += " "
+		tagsStr = " " + tagsStr
+	}
+
+	return tagsStr
+}
+
+// SQLFieldsStrForSet generate sqlstr in db from Fields for set,
+// select-only (expr) fields are omitted
+// example:" `field0` = ?, `field1` = ?, `field2` = ?, `field3` = ? "
+func (fds *_FieldsMap) SQLFieldsStrForSet() string {
+
+	var tagsStr string
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if len(fds.fields[i].expr) > 0 {
+			continue
+		}
+		if len(tagsStr) > 0 {
+			tagsStr += ", "
+		}
+		tagsStr += fds.quote(fds.fields[i].Tag)
+		tagsStr += " = ?"
+	}
+	if len(tagsStr) > 0 {
+		tagsStr += " "
+		tagsStr = " " + tagsStr
+	}
+
+	return tagsStr
+}
+
+// nonKeyFieldsStrForSet like SQLFieldsStrForSet without primary key
+// example:" `field1` = ?, `field2` = ?, `field3` = ? "
+func (fds *_FieldsMap) nonKeyFieldsStrForSet() string {
+
+	var tagsStr string
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if fds.isPriKey(i) || len(fds.fields[i].expr) > 0 {
+			continue
+		}
+		if len(tagsStr) > 0 {
+			tagsStr += ", "
+		}
+		tagsStr += fds.quote(fds.fields[i].Tag)
+		tagsStr += " = ?"
+	}
 	if len(tagsStr) > 0 {
 		tagsStr += " "
 		tagsStr = " " + tagsStr
@@ -322,6 +1327,50 @@ func (fds *_FieldsMap) SQLFieldsStrForSet() string {
 	return tagsStr
 }
 
+// nonKeyFieldValues like GetFieldValues without primary key
+func (fds *_FieldsMap) nonKeyFieldValues() []interface{} {
+
+	var values []interface{}
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if fds.isPriKey(i) || len(fds.fields[i].expr) > 0 {
+			continue
+		}
+		values = append(values, fds.GetFieldValue(i))
+	}
+
+	return values
+}
+
+// selectFieldsStr like SQLFieldsStr with select-only (expr) fields
+// example:" `field0`, (price * qty) AS `total` "
+func (fds *_FieldsMap) selectFieldsStr() string {
+
+	var tagsStr string
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if len(tagsStr) > 0 {
+			tagsStr += ", "
+		}
+		tagsStr += fds.selectColumn(i)
+	}
+	if len(tagsStr) > 0 {
+		tagsStr += " "
+		tagsStr = " " + tagsStr
+	}
+
+	return tagsStr
+}
+
+// selectColumn column of field idx in SELECT
+// example:"`field0`" or "(price * qty) AS `total`"
+func (fds *_FieldsMap) selectColumn(idx int) string {
+
+	if len(fds.fields[idx].expr) > 0 {
+		return "(" + fds.fields[idx].expr + ") AS " + fds.quote(fds.fields[idx].Tag)
+	}
+
+	return fds.quote(fds.fields[idx].Tag)
+}
+
 ////////////////////////////////////////////////////////////////
 // generate statement
 
@@ -329,204 +1378,655 @@ func (fds *_FieldsMap) SQLFieldsStrForSet() string {
 func (fds *_FieldsMap) PrepareStmt(ctx context.Context, tx *sql.Tx, db *sql.DB,
 	sqlstr string) (*sql.Stmt, error) {
 
-	if tx != nil {
-		return tx.PrepareContext(ctx, sqlstr)
-	}
-
-	if db != nil {
-		return db.PrepareContext(ctx, sqlstr)
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, errors.New("tx & db both nil")
+	return fds.prepare(ctx, exec, sqlstr)
 }
 
 // SQLSelectStmt generate statement for SELECT
 func (fds *_FieldsMap) SQLSelectStmt(ctx context.Context, tx *sql.Tx, db *sql.DB,
 	extStr string) (*sql.Stmt, error) {
 
-	sqlstr := "SELECT " + fds.SQLFieldsStr() +
-		" FROM `" + fds.table + "` " + extStr
+	return fds.PrepareStmt(ctx, tx, db, fds.SelectSQL(extStr))
+}
+
+// SelectSQL sqlstr prepared by SQLSelectStmt, without db, e.g. to log or test,
+// placeholders are rendered by dialect
+// example:"SELECT  `field0`, `field1`  FROM `t`  where `field0` = ? "
+func (fds *_FieldsMap) SelectSQL(extStr string) string {
+
+	return fds.rebind(fds.selectSQL(extStr))
+}
+
+// selectSQL generate sqlstr for SELECT
+func (fds *_FieldsMap) selectSQL(extStr string) string {
 
-	return fds.PrepareStmt(ctx, tx, db, sqlstr)
+	return fds.verb("SELECT") + fds.selectFieldsStr() +
+		" FROM " + fds.quoteTable() + " " + extStr
 }
 
 // SQLInsertStmt generate statement for INSERT
 func (fds *_FieldsMap) SQLInsertStmt(ctx context.Context, tx *sql.Tx, db *sql.DB) (*sql.Stmt, error) {
 
-	var vs string
+	return fds.PrepareStmt(ctx, tx, db, fds.InsertSQL())
+}
+
+// InsertSQL sqlstr prepared by SQLInsertStmt
+func (fds *_FieldsMap) InsertSQL() string {
+
+	return fds.rebind(fds.insertSQL())
+}
+
+// insertSQL generate sqlstr for INSERT
+func (fds *_FieldsMap) insertSQL() string {
+
+	var tagsStr string
+	n := 0
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if len(fds.fields[i].expr) > 0 || fds.fields[i].auto {
+			continue
+		}
+		if len(tagsStr) > 0 {
+			tagsStr += ", "
+		}
+		tagsStr += fds.quote(fds.fields[i].Tag)
+		n++
+	}
+
+	return fds.verb("INSERT") + "INTO " + fds.quoteTable() + " ( " + tagsStr + " ) " +
+		"VALUES (" + placeholders(n) + ")"
+}
+
+// insertValues values to bind for insertSQL,
+// GetFieldValues without db assigned (`auto`) primary key
+func (fds *_FieldsMap) insertValues() []interface{} {
+
+	var values []interface{}
 	for i, flen := 0, len(fds.fields); i < flen; i++ {
-		if len(vs) > 0 {
-			vs += ", "
+		if len(fds.fields[i].expr) > 0 || fds.fields[i].auto {
+			continue
 		}
-		vs += "?"
+		values = append(values, fds.GetFieldValue(i))
+	}
+
+	return values
+}
+
+// setAutoKey set db assigned (`auto`) primary key to LastInsertId of res,
+// kept if the driver has no LastInsertId (e.g. Postgres, see SQLInsertReturning)
+func (fds *_FieldsMap) setAutoKey(res sql.Result) error {
+
+	if !fds.fields[fds.pk].auto {
+		return nil
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil
 	}
 
-	sqlstr := "INSERT INTO `" + fds.table + "` (" + fds.SQLFieldsStr() + ") " +
-		"VALUES (" + vs + ")"
-	return fds.PrepareStmt(ctx, tx, db, sqlstr)
+	return setNarrowInt(fds.fields[fds.pk].Addr, id)
 }
 
 // SQLUpdateStmt generate statement for UPDATE
 func (fds *_FieldsMap) SQLUpdateStmt(ctx context.Context, tx *sql.Tx, db *sql.DB,
 	extStr string) (*sql.Stmt, error) {
 
-	sqlstr := "UPDATE `" + fds.table + "` SET " + fds.SQLFieldsStrForSet() + extStr
-	return fds.PrepareStmt(ctx, tx, db, sqlstr)
+	return fds.PrepareStmt(ctx, tx, db, fds.UpdateSQL(extStr))
+}
+
+// UpdateSQL sqlstr prepared by SQLUpdateStmt with extStr
+func (fds *_FieldsMap) UpdateSQL(extStr string) string {
+
+	return fds.rebind(fds.updateSQL(extStr))
+}
+
+// updateSQL generate sqlstr for UPDATE
+func (fds *_FieldsMap) updateSQL(extStr string) string {
+
+	return fds.verb("UPDATE") + fds.quoteTable() + " SET " + fds.SQLFieldsStrForSet() + extStr
 }
 
 // SQLDeleteStmt generate statement for DELETE
 func (fds *_FieldsMap) SQLDeleteStmt(ctx context.Context, tx *sql.Tx, db *sql.DB,
 	extStr string) (*sql.Stmt, error) {
 
-	sqlstr := "DELETE FROM `" + fds.table + "` " + extStr
-	return fds.PrepareStmt(ctx, tx, db, sqlstr)
+	return fds.PrepareStmt(ctx, tx, db, fds.DeleteSQL(extStr))
+}
+
+// DeleteSQL sqlstr prepared by SQLDeleteStmt with extStr
+func (fds *_FieldsMap) DeleteSQL(extStr string) string {
+
+	return fds.rebind(fds.deleteSQL(extStr))
+}
+
+// deleteSQL generate sqlstr for DELETE
+func (fds *_FieldsMap) deleteSQL(extStr string) string {
+
+	return fds.verb("DELETE") + "FROM " + fds.quoteTable() + " " + extStr
 }
 
 ////////////////////////////////////////////////////////////////
 // exec sql
 
-// SQLLockByPriKey by primary key (field[0])
+// SQLLockByPriKey by primary key, SELECT ... FOR UPDATE,
+// *NotFoundError if no row, errors.Is(err, ErrNotFound) is true
 func (fds *_FieldsMap) SQLLockByPriKey(ctx context.Context, tx *sql.Tx,
 	db *sql.DB) (interface{}, error) {
 
-	extStr := " where `" + fds.fields[0].Tag + "` = ? for update "
-	stmt, err := fds.SQLSelectStmt(ctx, tx, db, extStr)
+	exec, err := getExecutor(tx, db)
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close() // must close stmt after stmt used
-
-	r := stmt.QueryRowContext(ctx, fds.GetFieldValue(0))
-	if r == nil {
-		return nil, errors.New("row is nil")
-	}
 
-	err = r.Scan(fds.GetFieldSaveAddrs()...)
+	extStr, args := fds.scoped(" where "+fds.priKeyCond()+" for update ", fds.priKeyValues()...)
+	objptr, err := fds.selectOne(ctx, exec, fds.selectSQL(extStr), args...)
 	if err != nil {
-		return nil, err
+		return nil, fds.notFound(err)
 	}
 
-	return fds.MapBackToObject(), nil
+	return objptr, nil
 }
 
-// SQLSelectByPriKey by primary key (field[0])
+// SQLSelectByPriKey by primary key,
+// *NotFoundError if no row, errors.Is(err, ErrNotFound) is true
 func (fds *_FieldsMap) SQLSelectByPriKey(ctx context.Context, tx *sql.Tx,
 	db *sql.DB) (interface{}, error) {
 
-	extStr := " where `" + fds.fields[0].Tag + "` = ? "
-	stmt, err := fds.SQLSelectStmt(ctx, tx, db, extStr)
+	exec, err := getExecutor(tx, db)
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close() // must close stmt after stmt used
 
-	r := stmt.QueryRowContext(ctx, fds.GetFieldValue(0))
-	if r == nil {
-		return nil, errors.New("row is nil")
-	}
+	return fds.selectByPriKey(ctx, exec)
+}
+
+// selectByPriKey select row of primary key on exec
+func (fds *_FieldsMap) selectByPriKey(ctx context.Context, exec Executor) (interface{}, error) {
 
-	err = r.Scan(fds.GetFieldSaveAddrs()...)
+	err := fds.checkVault()
 	if err != nil {
 		return nil, err
 	}
 
-	return fds.MapBackToObject(), nil
+	extStr, args := fds.scoped(" where "+fds.priKeyCond()+" ", fds.priKeyValues()...)
+	objptr, err := fds.selectOne(ctx, exec, fds.selectSQL(extStr), args...)
+	if err != nil {
+		return nil, fds.notFound(err)
+	}
+	if fds.vault == nil {
+		return objptr, nil
+	}
+
+	return fds.vault.SQLSelectByPriKey(ctx, nil, fds.vaultDB)
 }
 
-// SQLSelectRowsByFieldNameInDB by field name in DB
+// SQLSelectRowsByFieldNameInDB by field name in DB,
+// `nameInDB` IS NULL if the field value is NULL (nil pointer)
 func (fds *_FieldsMap) SQLSelectRowsByFieldNameInDB(ctx context.Context, tx *sql.Tx,
 	db *sql.DB, nameInDB string) ([]interface{}, error) {
 
-	idx := -1
-	for i, flen := 0, len(fds.fields); i < flen; i++ {
-		if fds.fields[i].Tag == nameInDB {
-			idx = i
-			break
+	idx := fds.fieldIndex(nameInDB)
+	if idx < 0 {
+		return nil, errors.New("no field match `sql` tag:" + nameInDB)
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	cond, condArgs := fds.eqCond(fds.fields[idx].Tag, fds.GetFieldValue(idx))
+	extStr, args := fds.scoped(" where "+cond+" ", condArgs...)
+	return fds.selectRows(ctx, exec, scanByPosition, fds.selectSQL(extStr), args...)
+}
+
+// SQLSelectByFields select rows matching all criteria, `sql` tag => value,
+// every tag is checked against fields and every value is bound,
+// nil value matches NULL
+// example: fds.SQLSelectByFields(ctx, tx, db,
+// 	map[string]interface{}{"field_one": "one", "field_thr": 3})
+// SELECT ... where `field_one` = ? AND `field_thr` = ?
+func (fds *_FieldsMap) SQLSelectByFields(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	criteria map[string]interface{}) ([]interface{}, error) {
+
+	if len(criteria) == 0 {
+		return nil, errors.New("no criteria to select by")
+	}
+
+	names := make([]string, 0, len(criteria))
+	for name := range criteria {
+		if fds.fieldIndex(name) < 0 {
+			return nil, errors.New("no field match `sql` tag:" + name)
 		}
+		names = append(names, name)
+	}
+	// stable SQL for the same set of tags
+	sort.Strings(names)
+
+	var conds []string
+	var condArgs []interface{}
+	for i, nlen := 0, len(names); i < nlen; i++ {
+		cond, args := fds.eqCond(names[i], criteria[names[i]])
+		conds = append(conds, cond)
+		condArgs = append(condArgs, args...)
 	}
 
-	if idx < 0 {
-		return nil, errors.New("no field match `sql` tag:" + nameInDB)
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
 	}
 
-	extStr := " where `" + fds.fields[idx].Tag + "` = ? "
-	stmt, err := fds.SQLSelectStmt(ctx, tx, db, extStr)
+	extStr, args := fds.scoped(" where "+strings.Join(conds, " AND ")+" ", condArgs...)
+	return fds.selectRows(ctx, exec, scanByPosition, fds.selectSQL(extStr), args...)
+}
+
+// SQLSelectAllRows
+func (fds *_FieldsMap) SQLSelectAllRows(ctx context.Context, tx *sql.Tx,
+	db *sql.DB) ([]interface{}, error) {
+
+	exec, err := getExecutor(tx, db)
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close() // must close stmt after stmt used
 
-	rs, err := stmt.QueryContext(ctx, fds.GetFieldValue(idx))
+	return fds.selectAllRows(ctx, exec)
+}
+
+// SQLSelect select rows by condition in extStr, args bind to extStr,
+// as SQLSelectStmt prepares with extStr
+// example: fds.SQLSelect(ctx, tx, db, " where `field_thr` > ? ", 10)
+func (fds *_FieldsMap) SQLSelect(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	extStr string, args ...interface{}) ([]interface{}, error) {
+
+	exec, err := getExecutor(tx, db)
 	if err != nil {
 		return nil, err
 	}
-	defer rs.Close() // should close Rows after used
 
-	var objs []interface{}
-	for rs.Next() {
-		obj := reflect.New(fds.reftype).Interface()
-		fieldsMap, err := NewFieldsMap(fds.table, obj)
+	extStr, args = fds.scoped(extStr, args...)
+	return fds.selectRows(ctx, exec, scanByPosition, fds.selectSQL(extStr), args...)
+}
+
+// selectAllRows select all rows on exec
+func (fds *_FieldsMap) selectAllRows(ctx context.Context, exec Executor) ([]interface{}, error) {
+
+	extStr, args := fds.scoped("")
+	return fds.selectRows(ctx, exec, scanByPosition, fds.selectSQL(extStr), args...)
+}
+
+// SQLInsert
+func (fds *_FieldsMap) SQLInsert(ctx context.Context, tx *sql.Tx,
+	db *sql.DB) error {
+
+	_, err := fds.SQLInsertResult(ctx, tx, db)
+	return err
+}
+
+// SQLInsertResult SQLInsert, return sql.Result of the INSERT,
+// LastInsertId of it is the id of an AUTO_INCREMENT table,
+// which is set to primary key field with `auto` tag option
+func (fds *_FieldsMap) SQLInsertResult(ctx context.Context, tx *sql.Tx,
+	db *sql.DB) (sql.Result, error) {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return fds.insert(ctx, exec, false)
+}
+
+// insert insert row of Object(struct) on exec, between insert hooks,
+// with returning the inserted row is scanned back, see insertRow
+func (fds *_FieldsMap) insert(ctx context.Context, exec Executor,
+	returning bool) (sql.Result, error) {
+
+	err := fds.checkVault()
+	if err != nil {
+		return nil, err
+	}
+
+	err = fds.callHook(ctx, hookBeforeInsert)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := fds.insertRow(ctx, exec, returning)
+	if err != nil {
+		return nil, err
+	}
+
+	err = fds.callHook(ctx, hookAfterInsert)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// insertRow insert row of main table (or vault table for vault map),
+// and the vault row. with returning the row of main table is scanned
+// back by INSERT ... RETURNING and nil sql.Result is returned
+func (fds *_FieldsMap) insertRow(ctx context.Context, exec Executor,
+	returning bool) (sql.Result, error) {
+
+	fds.fillStamps(true)
+	err := fds.checkValues()
+	if err != nil {
+		return nil, err
+	}
+
+	var res sql.Result
+	if returning {
+		fds.InvalidateQueryCache()
+		sqlstr := fds.insertSQL() + " RETURNING" + fds.selectFieldsStr()
+		_, err = fds.selectOne(ctx, exec, sqlstr, fds.insertValues()...)
+	} else {
+		res, err = fds.execSQL(ctx, exec, fds.insertSQL(), fds.insertValues()...)
+		if err == nil {
+			err = fds.setAutoKey(res)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if fds.vault != nil {
+		_, err = fds.vault.insertRow(ctx, fds.vaultDB, false)
 		if err != nil {
 			return nil, err
 		}
+	}
+
+	return res, nil
+}
+
+// SQLUpdateByPriKey by primary key,
+// only dirty fields if any field is Set, see Set,
+// no-op if row hash field is unchanged, see RowHashChanged,
+// ErrNoRowsAffected if no row updated with SetStrictUpdate
+func (fds *_FieldsMap) SQLUpdateByPriKey(ctx context.Context, tx *sql.Tx,
+	db *sql.DB) error {
+
+	_, err := fds.SQLUpdateResult(ctx, tx, db)
+	return err
+}
+
+// SQLUpdateResult SQLUpdateByPriKey, return sql.Result of the UPDATE
+// of main table, 0 rows affected if skipped by unchanged row hash
+func (fds *_FieldsMap) SQLUpdateResult(ctx context.Context, tx *sql.Tx,
+	db *sql.DB) (sql.Result, error) {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return fds.update(ctx, exec)
+}
+
+// update update row of Object(struct) by primary key on exec,
+// and its vault row, between update hooks
+func (fds *_FieldsMap) update(ctx context.Context, exec Executor) (sql.Result, error) {
+
+	err := fds.checkVault()
+	if err != nil {
+		return nil, err
+	}
+
+	err = fds.callHook(ctx, hookBeforeUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := fds.updateByPriKey(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
 
-		err = rs.Scan(fieldsMap.GetFieldSaveAddrs()...)
+	if fds.vault != nil {
+		_, err = fds.vault.updateByPriKey(ctx, fds.vaultDB)
 		if err != nil {
 			return nil, err
 		}
-		fieldsMap.MapBackToObject()
-		objs = append(objs, obj)
 	}
 
-	return objs, nil
+	err = fds.callHook(ctx, hookAfterUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
 }
 
-// SQLSelectAllRows
-func (fds *_FieldsMap) SQLSelectAllRows(ctx context.Context, tx *sql.Tx,
-	db *sql.DB) ([]interface{}, error) {
+// updateByPriKey update row of main table (or vault table for vault map)
+func (fds *_FieldsMap) updateByPriKey(ctx context.Context, exec Executor) (sql.Result, error) {
+
+	if !fds.RowHashChanged() {
+		return driver.RowsAffected(0), nil
+	}
 
-	stmt, err := fds.SQLSelectStmt(ctx, tx, db, "")
+	fds.fillStamps(false)
+	err := fds.checkValues()
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close() // must close stmt after stmt used
 
-	rs, err := stmt.QueryContext(ctx)
+	if idx := fds.versionIndex(); idx >= 0 {
+		return fds.updateVersioned(ctx, exec, idx)
+	}
+	if fds.dirty != nil {
+		return fds.updateDirty(ctx, exec)
+	}
+
+	extStr, args := fds.scoped(" where "+fds.priKeyCond()+" ", fds.priKeyValues()...)
+	values := fds.GetFieldValues()
+	values = append(values, args...)
+	res, err := fds.execSQL(ctx, exec, fds.updateSQL(extStr), values...)
 	if err != nil {
 		return nil, err
 	}
-	defer rs.Close() // should close Rows after used
 
-	var objs []interface{}
-	for rs.Next() {
-		obj := reflect.New(fds.reftype).Interface()
-		fieldsMap, err := NewFieldsMap(fds.table, obj)
+	err = fds.checkAffected(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SetStrictUpdate SQLUpdateByPriKey return ErrNoRowsAffected
+// when no row is updated, off by default.
+// MySQL counts changed rows only unless the DSN sets clientFoundRows=true,
+// so an update to the same values is reported too
+func (fds *_FieldsMap) SetStrictUpdate(on bool) {
+
+	fds.strictUpdate = on
+}
+
+// checkAffected ErrNoRowsAffected for a strict update of no row
+func (fds *_FieldsMap) checkAffected(res sql.Result) error {
+
+	if !fds.strictUpdate {
+		return nil
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRowsAffected
+	}
+
+	return nil
+}
+
+// SQLDeleteByPriKey by primary key
+func (fds *_FieldsMap) SQLDeleteByPriKey(ctx context.Context, tx *sql.Tx,
+	db *sql.DB) error {
+
+	_, err := fds.SQLDeleteResult(ctx, tx, db)
+	return err
+}
+
+// SQLDeleteResult SQLDeleteByPriKey, return sql.Result of the DELETE
+// of main table
+func (fds *_FieldsMap) SQLDeleteResult(ctx context.Context, tx *sql.Tx,
+	db *sql.DB) (sql.Result, error) {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return fds.deleteByPriKey(ctx, exec, false)
+}
+
+// deleteByPriKey delete row of primary key on exec, and its vault row,
+// between delete hooks, a struct with softdelete field is only marked
+// deleted unless force
+func (fds *_FieldsMap) deleteByPriKey(ctx context.Context, exec Executor,
+	force bool) (sql.Result, error) {
+
+	err := fds.checkVault()
+	if err != nil {
+		return nil, err
+	}
+
+	err = fds.callHook(ctx, hookBeforeDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	var res sql.Result
+	if idx := fds.softDeleteIndex(); idx >= 0 && !force {
+		res, err = fds.softDelete(ctx, exec, idx)
 		if err != nil {
 			return nil, err
 		}
+		return res, fds.callHook(ctx, hookAfterDelete)
+	}
 
-		err = rs.Scan(fieldsMap.GetFieldSaveAddrs()...)
+	if fds.vault != nil {
+		_, err = fds.vault.deleteRow(ctx, fds.vaultDB)
 		if err != nil {
 			return nil, err
 		}
-		fieldsMap.MapBackToObject()
-		objs = append(objs, obj)
 	}
 
-	return objs, nil
+	res, err = fds.deleteRow(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
+
+	err = fds.callHook(ctx, hookAfterDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// deleteRow delete row of primary key of main table (or vault table for vault map)
+func (fds *_FieldsMap) deleteRow(ctx context.Context, exec Executor) (sql.Result, error) {
+
+	// trashed rows are deleted too
+	extStr, args := fds.scopedBy(" where "+fds.priKeyCond()+" ", false, fds.priKeyValues()...)
+	return fds.execSQL(ctx, exec, fds.deleteSQL(extStr), args...)
+}
+
+// SQLUpdateManyByPriKey update objects by primary key,
+// UPDATE is prepared once and executed for each object,
+// objptrs must point to the same struct type as fds
+// return total rows affected
+func (fds *_FieldsMap) SQLUpdateManyByPriKey(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, objptrs []interface{}) (int64, error) {
+
+	if len(objptrs) == 0 {
+		return 0, nil
+	}
+
+	var rowMaps []*_FieldsMap
+	for i, olen := 0, len(objptrs); i < olen; i++ {
+		fieldsMap, err := fds.sameTypeRowMap(objptrs[i])
+		if err != nil {
+			return 0, err
+		}
+		err = fieldsMap.checkValues()
+		if err != nil {
+			return 0, err
+		}
+		rowMaps = append(rowMaps, fieldsMap)
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	// primary keys are the last args, replaced for each object
+	extStr, args := fds.scoped(" where "+fds.priKeyCond()+" ", make([]interface{}, len(fds.pks))...)
+	sqlstr := fds.updateSQL(extStr)
+	stmt, release, err := fds.prepareShared(ctx, exec, sqlstr)
+	if err != nil {
+		return 0, err
+	}
+	defer release() // must release stmt after stmt used
+
+	var total int64
+	for i, rlen := 0, len(rowMaps); i < rlen; i++ {
+		values := rowMaps[i].GetFieldValues()
+		copy(args[len(args)-len(fds.pks):], rowMaps[i].priKeyValues())
+		values = append(values, args...)
+		done := fds.logStart(ctx, sqlstr, values)
+		res, err := stmt.ExecContext(ctx, values...)
+		err = classifyErr(err)
+		done(err)
+		if err != nil {
+			return total, err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+
+	return total, nil
 }
 
-// SQLInsert
-func (fds *_FieldsMap) SQLInsert(ctx context.Context, tx *sql.Tx,
-	db *sql.DB) error {
+// SQLUpdateByCond by condition in extStr, args bind to extStr
+// primary key is not updated.
+// example: fds.SQLUpdateByCond(ctx, tx, db, " where `field_thr` > ? ", 10)
+func (fds *_FieldsMap) SQLUpdateByCond(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, extStr string, args ...interface{}) error {
+
+	err := fds.noVault("SQLUpdateByPriKey")
+	if err != nil {
+		return err
+	}
+
+	err = fds.checkValues()
+	if err != nil {
+		return err
+	}
 
-	stmt, err := fds.SQLInsertStmt(ctx, tx, db)
+	exec, err := getExecutor(tx, db)
 	if err != nil {
 		return err
 	}
-	defer stmt.Close() // must close stmt after stmt used
 
-	_, err = stmt.ExecContext(ctx, fds.GetFieldValues()...)
+	extStr, args = fds.scoped(extStr, args...)
+	sqlstr := fds.verb("UPDATE") + fds.quoteTable() + " SET " + fds.nonKeyFieldsStrForSet() + extStr
+	values := fds.nonKeyFieldValues()
+	values = append(values, args...)
+	_, err = fds.execSQL(ctx, exec, sqlstr, values...)
 	if err != nil {
 		return err
 	}
@@ -534,42 +2034,218 @@ func (fds *_FieldsMap) SQLInsert(ctx context.Context, tx *sql.Tx,
 	return nil
 }
 
-// SQLUpdateByPriKey by primary key (field[0])
-func (fds *_FieldsMap) SQLUpdateByPriKey(ctx context.Context, tx *sql.Tx,
-	db *sql.DB) error {
+// SQLUpdateByCondReturningKeys by condition in extStr,
+// return primary keys of updated rows.
+// keys are locked by SELECT ... FOR UPDATE then updated in the same tx,
+// a new tx is used when tx is nil, error on composite primary key
+func (fds *_FieldsMap) SQLUpdateByCondReturningKeys(ctx context.Context,
+	tx *sql.Tx, db *sql.DB, extStr string, args ...interface{}) ([]interface{}, error) {
+
+	if err := fds.singlePriKey(); err != nil {
+		return nil, err
+	}
+
+	var keys []interface{}
+	err := withTx(ctx, tx, db, func(tx *sql.Tx) error {
+
+		lockExt, lockArgs := fds.scoped(extStr+" for update ", args...)
+		sqlstr := fds.verb("SELECT") + fds.quote(fds.fields[fds.pk].Tag) + " FROM " + fds.quoteTable() + " " + lockExt
+		err := fds.queryRows(ctx, tx, sqlstr, lockArgs, func(rs *sql.Rows) error {
+			var err error
+			keys, err = fds.scanKeys(rs)
+			return err
+		})
+		if err != nil {
+			return err
+		}
 
-	extStr := " where `" + fds.fields[0].Tag + "` = ? "
-	stmt, err := fds.SQLUpdateStmt(ctx, tx, db, extStr)
+		if len(keys) == 0 {
+			return nil
+		}
+
+		return fds.SQLUpdateByCond(ctx, tx, nil, extStr, args...)
+	})
 	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// scanKeys scan primary key of each row
+func (fds *_FieldsMap) scanKeys(rs *sql.Rows) ([]interface{}, error) {
+
+	keys := []interface{}{}
+	for rs.Next() {
+		obj := reflect.New(fds.reftype).Interface()
+		fieldsMap, err := fds.newRowMap(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		err = scanRow(rs, fieldsMap.GetFieldSaveAddr(fds.pk))
+		if err != nil {
+			return nil, err
+		}
+		err = fieldsMap.mapBackField(fds.pk)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fieldsMap.GetFieldValue(fds.pk))
+	}
+
+	if err := rs.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// SQLForEachRow call fn for each row in table, rows are fetched
+// chunkSize at a time by keyset pagination on primary key:
+// " where `pk` > ? order by `pk` limit ? "
+// an error from fn stops iteration and is returned,
+// error on composite primary key
+func (fds *_FieldsMap) SQLForEachRow(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, chunkSize int, fn func(obj interface{}) error) error {
+
+	if chunkSize <= 0 {
+		return errors.New("chunkSize must be positive")
+	}
+
+	if err := fds.singlePriKey(); err != nil {
 		return err
 	}
-	defer stmt.Close() // must close stmt after stmt used
 
-	values := fds.GetFieldValues()
-	values = append(values, fds.GetFieldValue(0))
-	_, err = stmt.ExecContext(ctx, values...)
+	exec, err := getExecutor(tx, db)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	pk := fds.quote(fds.fields[fds.pk].Tag)
+	var lastKey interface{}
+	for first := true; ; first = false {
+
+		var extStr string
+		var args []interface{}
+		if first {
+			extStr, args = fds.scoped(" order by "+pk+" limit ? ", chunkSize)
+		} else {
+			extStr, args = fds.scoped(" where "+pk+" > ? order by "+pk+" limit ? ", lastKey, chunkSize)
+		}
+		objs, err := fds.selectRows(ctx, exec, scanByPosition, fds.selectSQL(extStr), args...)
+		if err != nil {
+			return err
+		}
+
+		for i, olen := 0, len(objs); i < olen; i++ {
+			err = fn(objs[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(objs) < chunkSize {
+			return nil
+		}
+
+		fieldsMap, err := fds.newRowMap(objs[len(objs)-1])
+		if err != nil {
+			return err
+		}
+		lastKey = fieldsMap.GetFieldValue(fds.pk)
+	}
 }
 
-// SQLDeleteByPriKey by primary key (field[0])
-func (fds *_FieldsMap) SQLDeleteByPriKey(ctx context.Context, tx *sql.Tx,
-	db *sql.DB) error {
+// SQLSelectEach select rows by condition in extStr in one query,
+// call fn with each row as it is scanned, rows are not collected,
+// an error from fn stops iteration (rows closed) and is returned
+// example: fds.SQLSelectEach(ctx, tx, db, " where `field_thr` > ? ",
+// 	func(obj interface{}) error { ...; return nil }, 10)
+func (fds *_FieldsMap) SQLSelectEach(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	extStr string, fn func(obj interface{}) error, args ...interface{}) error {
 
-	extStr := " where `" + fds.fields[0].Tag + "` = ? "
-	stmt, err := fds.SQLDeleteStmt(ctx, tx, db, extStr)
+	exec, err := getExecutor(tx, db)
 	if err != nil {
 		return err
 	}
-	defer stmt.Close() // must close stmt after stmt used
 
-	_, err = stmt.ExecContext(ctx, fds.GetFieldValue(0))
+	extStr, args = fds.scoped(extStr, args...)
+	_, err = fds.scanEach(ctx, exec, scanByPosition, fds.selectSQL(extStr), args,
+		func(fieldsMap *_FieldsMap) error {
+			return fn(fieldsMap.objptr)
+		})
+
+	return err
+}
+
+// SQLSelectRandom select n random rows, extStr is an optional WHERE
+// example: fds.SQLSelectRandom(ctx, tx, db, 10, " where `field_two` = ? ", true)
+// SELECT ... extStr ORDER BY RAND() LIMIT ?
+// with Postgres dialect ORDER BY RANDOM()
+func (fds *_FieldsMap) SQLSelectRandom(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, n int, extStr string, args ...interface{}) ([]interface{}, error) {
+
+	if n <= 0 {
+		return []interface{}{}, nil
+	}
+
+	exec, err := getExecutor(tx, db)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	random := "RAND()"
+	if _, pg := fds.dialect.(postgresDialect); pg {
+		random = "RANDOM()"
+	}
+
+	binds := make([]interface{}, 0, len(args)+1)
+	binds = append(binds, args...)
+	binds = append(binds, n)
+	extStr, args = fds.scoped(extStr+" ORDER BY "+random+" LIMIT ? ", binds...)
+	return fds.selectRows(ctx, exec, scanByPosition, fds.selectSQL(extStr), args...)
+}
+
+// SQLSelectGroupBy select rows grouped by value of field with `sql` tag,
+// extStr & args for WHERE etc.
+// Group keys are values of the field type (e.g. string for a string field,
+// time.Time in UTC for time fields), rows keep query order in each group.
+// example: groups, err := fds.SQLSelectGroupBy(ctx, tx, db, "field_one", "")
+// groups["one"] is []interface{}{*DemoRow, ...}
+func (fds *_FieldsMap) SQLSelectGroupBy(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, nameInDB string, extStr string,
+	args ...interface{}) (map[interface{}][]interface{}, error) {
+
+	idx := fds.fieldIndex(nameInDB)
+	if idx < 0 {
+		return nil, errors.New("no field match `sql` tag:" + nameInDB)
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	extStr, args = fds.scoped(extStr, args...)
+	objs, err := fds.selectRows(ctx, exec, scanByPosition, fds.selectSQL(extStr), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[interface{}][]interface{}{}
+	for i, olen := 0, len(objs); i < olen; i++ {
+		fieldsMap, err := fds.newRowMap(objs[i])
+		if err != nil {
+			return nil, err
+		}
+
+		key := fieldsMap.fieldGoValue(idx)
+		if t, ok := key.(time.Time); ok {
+			key = t.UTC()
+		}
+		groups[key] = append(groups[key], objs[i])
+	}
+
+	return groups, nil
 }
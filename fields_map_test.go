@@ -3,7 +3,13 @@ package sqlmapper
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 var (
@@ -184,3 +190,1275 @@ func Remove(ctx context.Context, tx *sql.Tx, db *sql.DB, fieldKey string) error
 
 	return nil
 }
+
+func TestSQLUpdateByCondReturningKeys(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return &fakeResult{
+				cols: []string{"field_key"},
+				rows: [][]driver.Value{{"key001"}, {"key002"}},
+			}
+		}
+		return &fakeResult{affected: 2}
+	})
+	defer db.Close()
+
+	row := DemoRow{FieldOne: "updated"}
+	fm, err := NewFieldsMap(table, &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := fm.SQLUpdateByCondReturningKeys(context.Background(), nil, db,
+		" where `field_thr` > ? ", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] != "key001" || keys[1] != "key002" {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+
+	var got []string
+	for _, q := range fdb.Queries() {
+		got = append(got, q.sql)
+	}
+	want := []string{
+		"BEGIN",
+		"SELECT `field_key` FROM `test_table`  where `field_thr` > ?  for update ",
+		"UPDATE `test_table` SET  `field_one` = ?, `field_two` = ?, " +
+			"`field_thr` = ?, `field_fou` = ?  where `field_thr` > ? ",
+		"COMMIT",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if args := fdb.LastQuery().args; len(args) != 0 {
+		t.Errorf("commit with args %v", args)
+	}
+}
+
+func TestSQLForEachRow(t *testing.T) {
+
+	all := demoRowsResult(5)
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		limit := int(args[len(args)-1].(int64))
+		start := 0
+		if len(args) == 2 {
+			for start < len(all.rows) && all.rows[start][0].(string) <= args[0].(string) {
+				start++
+			}
+		}
+		end := start + limit
+		if end > len(all.rows) {
+			end = len(all.rows)
+		}
+		return &fakeResult{cols: all.cols, rows: all.rows[start:end]}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, err := NewFieldsMap(table, &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	err = fm.SQLForEachRow(ctx, nil, db, 2, func(obj interface{}) error {
+		keys = append(keys, obj.(*DemoRow).FieldKey)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(keys, ",") != "keya,keyb,keyc,keyd,keye" {
+		t.Errorf("got keys %v", keys)
+	}
+	if n := len(fdb.Queries()); n != 3 {
+		t.Errorf("got %d chunk queries, want 3", n)
+	}
+	want := "SELECT  `field_key`, `field_one`, `field_two`, `field_thr`, `field_fou`  " +
+		"FROM `test_table`  where `field_key` > ? order by `field_key` limit ? "
+	if q := fdb.LastQuery(); q.sql != want || q.args[0] != "keyd" {
+		t.Errorf("got %q %v", q.sql, q.args)
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err = fm.SQLForEachRow(ctx, nil, db, 2, func(obj interface{}) error {
+		n++
+		if n == 3 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 3 {
+		t.Errorf("got %v after %d rows, want stop after 3", err, n)
+	}
+}
+
+// scanNullFieldOne fake scan with `field_one` NULL
+func scanNullFieldOne(fm FieldsMap) {
+
+	fields := fm.GetFields()
+	fields[0].StringSave.String, fields[0].StringSave.Valid = "key001", true
+	fields[1].StringSave.Valid = false
+	fields[2].BoolSave.Bool, fields[2].BoolSave.Valid = true, true
+	fields[3].IntSave.Int64, fields[3].IntSave.Valid = 3, true
+	fields[4].FloatSave.Float64, fields[4].FloatSave.Valid = 4.5, true
+}
+
+func TestNullPolicyLeaveOnNull(t *testing.T) {
+
+	row := DemoRow{FieldOne: "stale"}
+	fm, _ := NewFieldsMap(table, &row)
+	fm.SetNullPolicy(LeaveOnNull)
+
+	scanNullFieldOne(fm)
+	fm.MapBackToObject()
+	if row.FieldOne != "stale" || row.FieldThr != 3 {
+		t.Errorf("LeaveOnNull got %+v", row)
+	}
+}
+
+func TestNullPolicyZeroOnNull(t *testing.T) {
+
+	row := DemoRow{FieldOne: "stale"}
+	fm, _ := NewFieldsMap(table, &row)
+	if fm.GetNullPolicy() != ZeroOnNull {
+		t.Fatal("ZeroOnNull should be the default")
+	}
+
+	scanNullFieldOne(fm)
+	fm.MapBackToObject()
+	if row.FieldOne != "" || row.FieldThr != 3 {
+		t.Errorf("ZeroOnNull got %+v", row)
+	}
+}
+
+func TestNullPolicySelectReused(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"field_key", "field_one", "field_two", "field_thr", "field_fou"},
+			rows: [][]driver.Value{{"key001", nil, nil, nil, 4.5}},
+		}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "key001", FieldOne: "stale", FieldTwo: true, FieldThr: 3}
+	fm, _ := NewFieldsMap(table, &row)
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if row.FieldOne != "" || row.FieldTwo || row.FieldThr != 0 || row.FieldFou != 4.5 {
+		t.Errorf("NULL columns should zero fields, got %+v", row)
+	}
+
+	row = DemoRow{FieldKey: "key001", FieldOne: "stale", FieldTwo: true, FieldThr: 3}
+	fm.SetNullPolicy(LeaveOnNull)
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if row.FieldOne != "stale" || !row.FieldTwo || row.FieldThr != 3 {
+		t.Errorf("LeaveOnNull should keep fields, got %+v", row)
+	}
+}
+
+func TestNullPolicyErrorOnNull(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"field_key", "field_one", "field_two", "field_thr", "field_fou"},
+			rows: [][]driver.Value{{"key001", nil, true, int64(3), 4.5}},
+		}
+	})
+	defer db.Close()
+
+	row := DemoRow{FieldKey: "key001"}
+	fm, _ := NewFieldsMap(table, &row)
+	fm.SetNullPolicy(ErrorOnNull)
+
+	_, err := fm.SQLSelectByPriKey(context.Background(), nil, db)
+	if !errors.Is(err, ErrUnexpectedNull) {
+		t.Errorf("got %v, want ErrUnexpectedNull", err)
+	}
+
+	_, err = fm.SQLSelectAllRows(context.Background(), nil, db)
+	if !errors.Is(err, ErrUnexpectedNull) {
+		t.Errorf("rows got %v, want ErrUnexpectedNull", err)
+	}
+}
+
+type timeRow struct {
+	ID      int64     `sql:"id"`
+	Created time.Time `sql:"created"`
+	SeenMs  time.Time `sql:"seen_ms,epoch=ms"`
+	SeenS   time.Time `sql:"seen_s,epoch=s"`
+}
+
+func TestTimeEpochBind(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+
+	now := time.Date(2024, 5, 6, 7, 8, 9, 123000000, time.UTC)
+	row := timeRow{ID: 1, Created: now, SeenMs: now}
+	fm, err := NewFieldsMap("time_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fm.SQLInsert(context.Background(), nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := fdb.LastQuery().args
+	if !args[1].(time.Time).Equal(now) {
+		t.Errorf("created bind %v", args[1])
+	}
+	if args[2] != now.UnixMilli() {
+		t.Errorf("seen_ms bind %v, want %d", args[2], now.UnixMilli())
+	}
+	if args[3] != nil {
+		t.Errorf("zero time should bind NULL, got %v", args[3])
+	}
+}
+
+func TestTimeEpochScan(t *testing.T) {
+
+	now := time.Date(2024, 5, 6, 7, 8, 9, 123000000, time.UTC)
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "created", "seen_ms", "seen_s"},
+			rows: [][]driver.Value{{int64(1), now, now.UnixMilli(), nil}},
+		}
+	})
+	defer db.Close()
+
+	row := timeRow{ID: 1}
+	fm, err := NewFieldsMap("time_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = fm.SQLSelectByPriKey(context.Background(), nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !row.Created.Equal(now) || !row.SeenMs.Equal(now) || !row.SeenS.IsZero() {
+		t.Errorf("unexpected %+v", row)
+	}
+}
+
+func TestTimeEpochInvalid(t *testing.T) {
+
+	var row struct {
+		ID int64 `sql:"id,epoch=ms"`
+	}
+	if _, err := NewFieldsMap("time_table", &row); err == nil {
+		t.Error("want error for epoch on int64")
+	}
+
+	var row2 struct {
+		TS time.Time `sql:"ts,epoch=us"`
+	}
+	if _, err := NewFieldsMap("time_table", &row2); err == nil {
+		t.Error("want error for unknown epoch unit")
+	}
+}
+
+func TestSQLSelectRandom(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return demoRowsResult(2)
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	objs, err := fm.SQLSelectRandom(context.Background(), nil, db, 2,
+		" where `field_two` = ? ", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 {
+		t.Errorf("got %d rows", len(objs))
+	}
+
+	q := fdb.LastQuery()
+	if !strings.HasSuffix(q.sql, " where `field_two` = ?  ORDER BY RAND() LIMIT ? ") {
+		t.Errorf("unexpected sql %q", q.sql)
+	}
+	if len(q.args) != 2 || q.args[0] != true || q.args[1] != int64(2) {
+		t.Errorf("unexpected args %v", q.args)
+	}
+
+	args := make([]interface{}, 1, 2)
+	args[0] = true
+	pg, _ := NewFieldsMapWithDialect(table, &row, Postgres)
+	if _, err := pg.SQLSelectRandom(context.Background(), nil, db, 2,
+		` where "field_two" = ? `, args...); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasSuffix(q.sql, ` where "field_two" = $1  ORDER BY RANDOM() LIMIT $2 `) {
+		t.Errorf("unexpected postgres sql %q", q.sql)
+	}
+	if args[:2][1] != nil {
+		t.Errorf("caller's args backing array written: %v", args[:2])
+	}
+}
+
+func TestSQLUpdateManyByPriKey(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{affected: 1}
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	rows := []DemoRow{
+		{FieldKey: "key001", FieldThr: 1},
+		{FieldKey: "key002", FieldThr: 2},
+		{FieldKey: "key003", FieldThr: 3},
+	}
+	objptrs := []interface{}{&rows[0], &rows[1], &rows[2]}
+	n, err := fm.SQLUpdateManyByPriKey(context.Background(), nil, db, objptrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("got %d rows affected, want 3", n)
+	}
+
+	if n := len(fdb.Prepares()); n != 1 {
+		t.Errorf("prepared %d times, want once", n)
+	}
+	qs := fdb.Queries()
+	if len(qs) != 3 {
+		t.Fatalf("got %d statements, want 3", len(qs))
+	}
+	for i, q := range qs {
+		if q.args[5] != rows[i].FieldKey || q.args[3] != rows[i].FieldThr {
+			t.Errorf("statement %d args %v", i, q.args)
+		}
+	}
+
+	var other timeRow
+	if _, err := fm.SQLUpdateManyByPriKey(context.Background(), nil, db,
+		[]interface{}{&other}); err == nil {
+		t.Error("want error for object of another type")
+	}
+}
+
+func TestSQLSelectGroupBy(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return demoRowsResult(5)
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	groups, err := fm.SQLSelectGroupBy(context.Background(), nil, db, "field_two", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || len(groups[true]) != 3 || len(groups[false]) != 2 {
+		t.Fatalf("unexpected groups %v", groups)
+	}
+	if groups[true][1].(*DemoRow).FieldKey != "keyc" {
+		t.Errorf("group should keep query order, got %+v", groups[true][1])
+	}
+
+	if _, err := fm.SQLSelectGroupBy(context.Background(), nil, db, "nope", ""); err == nil {
+		t.Error("want error for unknown field")
+	}
+}
+
+func TestSelectRowsErr(t *testing.T) {
+
+	readErr := errors.New("connection reset mid-result")
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		res := demoRowsResult(2)
+		res.rowsErr = readErr
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "keya"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	objs, err := fm.SQLSelectAllRows(ctx, nil, db)
+	if !errors.Is(err, readErr) || objs != nil {
+		t.Errorf("SQLSelectAllRows want read error, got %v %v", objs, err)
+	}
+
+	objs, err = fm.SQLSelectRowsByFieldNameInDB(ctx, nil, db, "field_key")
+	if !errors.Is(err, readErr) || objs != nil {
+		t.Errorf("SQLSelectRowsByFieldNameInDB want read error, got %v %v", objs, err)
+	}
+
+	if n := db.Stats().InUse; n != 0 {
+		t.Errorf("rows not closed, %d connections in use", n)
+	}
+}
+
+func TestPrimaryKeyValue(t *testing.T) {
+
+	row := DemoRow{FieldKey: "key001", FieldThr: 3}
+	fm, _ := NewFieldsMap(table, &row)
+	if v := fm.PrimaryKeyValue(); v != "key001" {
+		t.Errorf("got %v, want key001", v)
+	}
+
+	type empty struct{}
+	efm, _ := NewFieldsMap(table, &empty{})
+	if v := efm.PrimaryKeyValue(); v != nil {
+		t.Errorf("got %v, want nil", v)
+	}
+}
+
+type nullableRow struct {
+	ID    int64      `sql:"id"`
+	Name  *string    `sql:"name"`
+	Count *int64     `sql:"count"`
+	Score *float64   `sql:"score"`
+	Flag  *bool      `sql:"flag"`
+	Seen  *time.Time `sql:"seen"`
+}
+
+func TestPointerFieldInsertNil(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+
+	name := "bob"
+	row := nullableRow{ID: 1, Name: &name}
+	fm, err := NewFieldsMap("nullable_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.GetFields()[1].Type != "*string" {
+		t.Errorf("got type %q", fm.GetFields()[1].Type)
+	}
+
+	err = fm.SQLInsert(context.Background(), nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := fdb.LastQuery().args
+	if args[1] != "bob" {
+		t.Errorf("name bind %v, want bob", args[1])
+	}
+	for i := 2; i < len(args); i++ {
+		if args[i] != nil {
+			t.Errorf("nil pointer %d should bind NULL, got %v", i, args[i])
+		}
+	}
+}
+
+func TestPointerFieldScanNull(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "name", "count", "score", "flag", "seen"},
+			rows: [][]driver.Value{{int64(1), nil, int64(0), nil, false, nil}},
+		}
+	})
+	defer db.Close()
+
+	stale := "stale"
+	row := nullableRow{ID: 1, Name: &stale}
+	fm, _ := NewFieldsMap("nullable_table", &row)
+	fm.SetNullPolicy(ErrorOnNull)
+
+	_, err := fm.SQLSelectByPriKey(context.Background(), nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.Name != nil || row.Score != nil || row.Seen != nil {
+		t.Errorf("NULL should map back to nil, got %+v", row)
+	}
+	if row.Count == nil || *row.Count != 0 || row.Flag == nil || *row.Flag {
+		t.Errorf("zero values should be allocated, got %+v", row)
+	}
+	if stale != "stale" {
+		t.Errorf("old pointee changed to %q", stale)
+	}
+}
+
+func TestPointerFieldInvalid(t *testing.T) {
+
+	var row struct {
+		ID  int64      `sql:"id"`
+		Num *complex64 `sql:"num"`
+	}
+	if _, err := NewFieldsMap("nullable_table", &row); err == nil {
+		t.Error("want error for *complex64")
+	}
+}
+
+func TestSelectByNilField(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+
+	row := nullableRow{ID: 1}
+	fm, _ := NewFieldsMap("nullable_table", &row)
+
+	_, err := fm.SQLSelectRowsByFieldNameInDB(context.Background(), nil, db, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := fdb.LastQuery()
+	if !strings.HasSuffix(q.sql, " where `name` IS NULL ") || len(q.args) != 0 {
+		t.Errorf("nil field should be IS NULL, got %q %v", q.sql, q.args)
+	}
+
+	name := "bob"
+	row.Name = &name
+	_, err = fm.SQLSelectRowsByFieldNameInDB(context.Background(), nil, db, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q = fdb.LastQuery()
+	if !strings.HasSuffix(q.sql, " where `name` = ? ") || len(q.args) != 1 || q.args[0] != "bob" {
+		t.Errorf("got %q %v", q.sql, q.args)
+	}
+}
+
+func TestIsNullValue(t *testing.T) {
+
+	var sp *string
+	for _, v := range []interface{}{nil, sp, sql.NullString{}, sql.NullInt64{}} {
+		if !isNullValue(v) {
+			t.Errorf("%#v should be NULL", v)
+		}
+	}
+	for _, v := range []interface{}{"", int64(0), sql.NullString{Valid: true}} {
+		if isNullValue(v) {
+			t.Errorf("%#v should not be NULL", v)
+		}
+	}
+}
+
+type thirdKeyRow struct {
+	Name  string `sql:"name"`
+	Count int64  `sql:"count"`
+	ID    int64  `sql:"id,pk"`
+}
+
+func TestPriKeyTagOption(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"name", "count", "id"},
+			rows: [][]driver.Value{{"bob", int64(2), int64(9)}},
+		}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := thirdKeyRow{Name: "bob", Count: 2, ID: 9}
+	fm, err := NewFieldsMap("third_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.GetPriKeyIndex() != 2 || fm.PrimaryKeyValue() != int64(9) {
+		t.Fatalf("pk index %d value %v", fm.GetPriKeyIndex(), fm.PrimaryKeyValue())
+	}
+
+	_, err = fm.SQLSelectByPriKey(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasSuffix(q.sql, " where `id` = ? ") || q.args[0] != int64(9) {
+		t.Errorf("select got %q %v", q.sql, q.args)
+	}
+
+	_, err = fm.SQLLockByPriKey(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasSuffix(q.sql, " where `id` = ? for update ") {
+		t.Errorf("lock got %q", q.sql)
+	}
+
+	row.Count = 3
+	fdb.handler = nil
+	err = fm.SQLUpdateByPriKey(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := fdb.LastQuery()
+	if q.sql != "UPDATE `third_table` SET  `name` = ?, `count` = ?, `id` = ?  where `id` = ? " ||
+		len(q.args) != 4 || q.args[3] != int64(9) {
+		t.Errorf("update got %q %v", q.sql, q.args)
+	}
+
+	err = fm.SQLDeleteByPriKey(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasSuffix(q.sql, " where `id` = ? ") || q.args[0] != int64(9) {
+		t.Errorf("delete got %q %v", q.sql, q.args)
+	}
+
+	var demo DemoRow
+	dfm, _ := NewFieldsMap(table, &demo)
+	if dfm.GetPriKeyIndex() != 0 {
+		t.Errorf("pk should default to first field, got %d", dfm.GetPriKeyIndex())
+	}
+}
+
+type memberRow struct {
+	TenantID int64  `sql:"tenant_id,pk"`
+	Role     string `sql:"role"`
+	UserID   int64  `sql:"user_id,pk"`
+}
+
+func TestCompositePriKey(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return &fakeResult{
+				cols: []string{"tenant_id", "role", "user_id"},
+				rows: [][]driver.Value{{int64(7), "admin", int64(42)}},
+			}
+		}
+		return nil
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := memberRow{TenantID: 7, Role: "admin", UserID: 42}
+	fm, err := NewFieldsMap("members", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idxs := fm.GetPriKeyIndexes(); len(idxs) != 2 || idxs[0] != 0 || idxs[1] != 2 {
+		t.Fatalf("unexpected pk indexes %v", idxs)
+	}
+
+	err = fm.SQLInsert(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row.Role = ""
+	_, err = fm.SQLSelectByPriKey(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := fdb.LastQuery()
+	if !strings.HasSuffix(q.sql, " where `tenant_id` = ? AND `user_id` = ? ") ||
+		len(q.args) != 2 || q.args[0] != int64(7) || q.args[1] != int64(42) {
+		t.Errorf("select got %q %v", q.sql, q.args)
+	}
+	if row.Role != "admin" {
+		t.Errorf("select did not scan row, got %+v", row)
+	}
+
+	row.Role = "owner"
+	err = fm.SQLUpdateByPriKey(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q = fdb.LastQuery()
+	if !strings.HasSuffix(q.sql, " where `tenant_id` = ? AND `user_id` = ? ") ||
+		len(q.args) != 5 || q.args[1] != "owner" || q.args[3] != int64(7) || q.args[4] != int64(42) {
+		t.Errorf("update got %q %v", q.sql, q.args)
+	}
+
+	rows := []memberRow{{TenantID: 7, UserID: 1}, {TenantID: 8, UserID: 2}}
+	_, err = fm.SQLUpdateManyByPriKey(ctx, nil, db, []interface{}{&rows[0], &rows[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q = fdb.LastQuery()
+	if len(q.args) != 5 || q.args[3] != int64(8) || q.args[4] != int64(2) {
+		t.Errorf("update many got %q %v", q.sql, q.args)
+	}
+
+	err = fm.SQLDeleteByPriKey(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q = fdb.LastQuery()
+	if q.sql != "DELETE FROM `members`  where `tenant_id` = ? AND `user_id` = ? " ||
+		len(q.args) != 2 || q.args[1] != int64(42) {
+		t.Errorf("delete got %q %v", q.sql, q.args)
+	}
+
+	_, err = fm.SQLSelectByPriKeys(ctx, nil, db, []interface{}{int64(7)})
+	if err == nil {
+		t.Error("want error for SQLSelectByPriKeys on composite key")
+	}
+}
+
+func BenchmarkNewFieldsMap(b *testing.B) {
+
+	var row DemoRow
+	b.Run("parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			layout, err := parseStructLayout(reflect.TypeOf(row), []string{defaultTagKey})
+			if err != nil {
+				b.Fatal(err)
+			}
+			newFieldsMapFromLayout(table, &row, layout)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := NewFieldsMap(table, &row); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestSetStrictUpdate(t *testing.T) {
+
+	var affected int64
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{affected: affected}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "missing"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Errorf("lenient by default, got %v", err)
+	}
+
+	fm.SetStrictUpdate(true)
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); !errors.Is(err, ErrNoRowsAffected) {
+		t.Errorf("want ErrNoRowsAffected, got %v", err)
+	}
+	fm.Set("field_thr", 1)
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); !errors.Is(err, ErrNoRowsAffected) {
+		t.Errorf("dirty update want ErrNoRowsAffected, got %v", err)
+	}
+
+	affected = 1
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGeneratedSQL(t *testing.T) {
+
+	row := DemoRow{FieldKey: "key001"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	cases := []struct{ got, want string }{
+		{fm.SelectSQL(" where `field_thr` > ? "),
+			"SELECT  `field_key`, `field_one`, `field_two`, `field_thr`, `field_fou`  FROM `test_table`  where `field_thr` > ? "},
+		{fm.InsertSQL(),
+			"INSERT INTO `test_table` ( `field_key`, `field_one`, `field_two`, `field_thr`, `field_fou` ) VALUES (?, ?, ?, ?, ?)"},
+		{fm.UpdateSQL(" where `field_key` = ? "),
+			"UPDATE `test_table` SET  `field_key` = ?, `field_one` = ?, `field_two` = ?, `field_thr` = ?, `field_fou` = ?  where `field_key` = ? "},
+		{fm.DeleteSQL(" where `field_key` = ? "),
+			"DELETE FROM `test_table`  where `field_key` = ? "},
+	}
+	for i, c := range cases {
+		if c.got != c.want {
+			t.Errorf("case %d got %q, want %q", i, c.got, c.want)
+		}
+	}
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	stmt, err := fm.SQLDeleteStmt(context.Background(), nil, db, " where `field_key` = ? ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt.Close()
+	if p := fdb.Prepares(); p[len(p)-1] != cases[3].want {
+		t.Errorf("stmt prepared %q, want %q", p[len(p)-1], cases[3].want)
+	}
+
+	pg, _ := fm.View("", Postgres)
+	if s := pg.DeleteSQL(` where "field_key" = ? `); s != `DELETE FROM "test_table"  where "field_key" = $1 ` {
+		t.Errorf("postgres delete got %q", s)
+	}
+}
+
+func TestSkipTransientField(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return &fakeResult{cols: []string{"id", "name"}, rows: [][]driver.Value{{int64(1), "ann"}}}
+		}
+		return nil
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row struct {
+		ID        int64          `sql:"id"`
+		Name      string         `sql:"name"`
+		Transient string         `sql:"-"`
+		Cache     map[string]int `sql:"-"`
+	}
+	row.ID = 1
+	row.Transient = "display"
+	fm, err := NewFieldsMap("transient_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range fdb.Queries() {
+		if strings.Contains(q.sql, "``") || strings.Contains(q.sql, "-") {
+			t.Errorf("transient field in %q", q.sql)
+		}
+	}
+	if q := fdb.LastQuery(); len(q.args) != 2 {
+		t.Errorf("unexpected binds %v", q.args)
+	}
+	if row.Name != "ann" || row.Transient != "display" {
+		t.Errorf("unexpected %+v", row)
+	}
+}
+
+func TestSkipUnexportedField(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return &fakeResult{cols: []string{"id", "name"}, rows: [][]driver.Value{{int64(1), "ann"}}}
+		}
+		return nil
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row struct {
+		ID    int64 `sql:"id"`
+		mu    sync.Mutex
+		Name  string `sql:"name"`
+		loads int64  `sql:"loads"`
+	}
+	row.ID = 1
+	fm, err := NewFieldsMap("private_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row.mu.Lock()
+	row.loads++
+	row.mu.Unlock()
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); strings.Contains(q.sql, "loads") {
+		t.Errorf("unexported field in %q", q.sql)
+	}
+	if row.Name != "ann" || row.loads != 1 {
+		t.Errorf("unexpected %q %d", row.Name, row.loads)
+	}
+}
+
+func TestSQLSelectByFields(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return demoRowsResult(1)
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	objs, err := fm.SQLSelectByFields(ctx, nil, db, map[string]interface{}{
+		"field_thr": int64(3),
+		"field_one": "one",
+		"field_fou": nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 1 {
+		t.Errorf("unexpected rows %v", objs)
+	}
+	q := fdb.LastQuery()
+	want := "SELECT  `field_key`, `field_one`, `field_two`, `field_thr`, `field_fou`  FROM `test_table`  where `field_fou` IS NULL AND `field_one` = ? AND `field_thr` = ? "
+	if q.sql != want || len(q.args) != 2 || q.args[0] != "one" || q.args[1] != int64(3) {
+		t.Errorf("got %q %v", q.sql, q.args)
+	}
+
+	_, err = fm.SQLSelectByFields(ctx, nil, db, map[string]interface{}{"field_one = 1 OR 1": 1})
+	if err == nil || !strings.Contains(err.Error(), "no field match") {
+		t.Errorf("want error for unknown column, got %v", err)
+	}
+	if _, err := fm.SQLSelectByFields(ctx, nil, db, nil); err == nil {
+		t.Error("want error for no criteria")
+	}
+}
+
+func TestWriteResults(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		switch {
+		case strings.HasPrefix(q, "INSERT"):
+			return &fakeResult{affected: 1, lastID: 42}
+		case strings.HasPrefix(q, "UPDATE"):
+			return &fakeResult{affected: 1}
+		default:
+		}
+		return &fakeResult{affected: 0}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "keya"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	res, err := fm.SQLInsertResult(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := res.LastInsertId(); id != 42 {
+		t.Errorf("got LastInsertId %d, want 42", id)
+	}
+
+	res, err = fm.SQLUpdateResult(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Errorf("update got %d rows affected", n)
+	}
+
+	res, err = fm.SQLDeleteResult(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 0 {
+		t.Errorf("delete got %d rows affected", n)
+	}
+}
+
+type autoRow struct {
+	ID   int64  `sql:"id,pk,auto"`
+	Name string `sql:"name"`
+}
+
+func TestAutoIncrementPriKey(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{affected: 1, lastID: 17}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := autoRow{Name: "ann"}
+	fm, err := NewFieldsMap("auto_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	q := fdb.LastQuery()
+	if q.sql != "INSERT INTO `auto_table` ( `name` ) VALUES (?)" || len(q.args) != 1 || q.args[0] != "ann" {
+		t.Errorf("unexpected insert %q %v", q.sql, q.args)
+	}
+	if row.ID != 17 {
+		t.Errorf("id not set from LastInsertId: %d", row.ID)
+	}
+
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.sql != "UPDATE `auto_table` SET  `id` = ?, `name` = ?  where `id` = ? " {
+		t.Errorf("unexpected update %q", q.sql)
+	}
+
+	var bad struct {
+		ID   int64  `sql:"id,auto"`
+		Name string `sql:"name"`
+	}
+	if _, err := NewFieldsMap("auto_table", &bad); err == nil {
+		t.Error("want error for auto option on non primary key")
+	}
+}
+
+func TestAutoIncrementUintPriKey(t *testing.T) {
+
+	lastID := int64(17)
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{affected: 1, lastID: lastID}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row struct {
+		ID   uint64 `sql:"id,pk,auto"`
+		Name string `sql:"name"`
+	}
+	fm, err := NewFieldsMap("auto_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if row.ID != 17 {
+		t.Errorf("id not set from LastInsertId: %d", row.ID)
+	}
+
+	var narrow struct {
+		ID   uint   `sql:"id,pk,auto"`
+		Name string `sql:"name"`
+	}
+	nfm, _ := NewFieldsMap("auto_table", &narrow)
+	lastID = -1
+	if err := nfm.SQLInsert(ctx, nil, db); err == nil {
+		t.Error("want error for negative LastInsertId on uint key")
+	}
+}
+
+func TestTableNameValidation(t *testing.T) {
+
+	var row DemoRow
+	for _, bad := range []string{
+		"",
+		"test_table`; DROP TABLE users; --",
+		"test table",
+		"test-table",
+		"1table",
+		"db.schema.table",
+		"test_table WHERE 1 = 1",
+	} {
+		if _, err := NewFieldsMap(bad, &row); err == nil {
+			t.Errorf("want error for table %q", bad)
+		}
+	}
+	if _, err := NewMapper[DemoRow]("t`x"); err == nil {
+		t.Error("want error from NewMapper")
+	}
+
+	fm, err := NewFieldsMap("shop.test_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := fm.SelectSQL(""); !strings.Contains(s, " FROM `shop`.`test_table` ") {
+		t.Errorf("schema should be quoted apart, got %q", s)
+	}
+	if _, err := fm.View("v2; --", nil); err == nil {
+		t.Error("want error from View")
+	}
+}
+
+type Timestamps struct {
+	CreatedAt time.Time `sql:"created_at"`
+	UpdatedAt time.Time `sql:"updated_at"`
+}
+
+type audit struct {
+	Editor string `sql:"editor"`
+}
+
+type EmbedRow struct {
+	FieldKey string `sql:"field_key,pk"`
+	Timestamps
+	audit
+	FieldOne string `sql:"field_one"`
+}
+
+func TestEmbeddedStruct(t *testing.T) {
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return &fakeResult{
+				cols: []string{"field_key", "created_at", "updated_at", "editor", "field_one"},
+				rows: [][]driver.Value{{"keya", ts, ts.Add(time.Hour), "bob", "one"}},
+			}
+		}
+		return nil
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := EmbedRow{FieldKey: "keya", FieldOne: "one"}
+	row.CreatedAt = ts
+	row.Editor = "ann"
+	fm, err := NewFieldsMap(table, &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := fm.SQLFieldsStr(); s != " `field_key`, `created_at`, `updated_at`, `editor`, `field_one` " {
+		t.Errorf("unexpected fields %q", s)
+	}
+	if fs := fm.GetFields(); fs[1].Addr != &row.Timestamps.CreatedAt || fs[3].Addr != &row.audit.Editor {
+		t.Error("Addr of embedded field should point into the embedded struct")
+	}
+
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.args[1] != ts || q.args[3] != "ann" {
+		t.Errorf("unexpected insert args %v", q.args)
+	}
+
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if !row.UpdatedAt.Equal(ts.Add(time.Hour)) || row.Editor != "bob" {
+		t.Errorf("embedded fields not scanned: %+v", row)
+	}
+
+	objs, err := fm.SQLSelectAllRows(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := objs[0].(*EmbedRow); !got.CreatedAt.Equal(ts) || got.Editor != "bob" {
+		t.Errorf("embedded fields not scanned in rows: %+v", got)
+	}
+}
+
+func TestSQLSelectEach(t *testing.T) {
+
+	errRows := errors.New("rows broken")
+	res := demoRowsResult(5)
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	var keys []string
+	err := fm.SQLSelectEach(ctx, nil, db, " where `field_thr` >= ? ", func(obj interface{}) error {
+		keys = append(keys, obj.(*DemoRow).FieldKey)
+		return nil
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(keys, ",") != "keya,keyb,keyc,keyd,keye" {
+		t.Errorf("got keys %v", keys)
+	}
+	if q := fdb.LastQuery(); !strings.HasSuffix(q.sql, " where `field_thr` >= ? ") || len(fdb.Queries()) != 1 {
+		t.Errorf("want one query, got %q", q.sql)
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err = fm.SQLSelectEach(ctx, nil, db, "", func(obj interface{}) error {
+		n++
+		if n == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 2 {
+		t.Errorf("got %v after %d rows, want stop after 2", err, n)
+	}
+	if s := db.Stats(); s.InUse != 0 {
+		t.Errorf("rows should be closed after stop, %d conns in use", s.InUse)
+	}
+
+	res.rowsErr = errRows
+	n = 0
+	err = fm.SQLSelectEach(ctx, nil, db, "", func(obj interface{}) error {
+		n++
+		return nil
+	})
+	if !errors.Is(err, errRows) || n != 5 {
+		t.Errorf("want rows error after 5 rows, got %v after %d", err, n)
+	}
+}
+
+func TestRebind(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	a := DemoRow{FieldKey: "keya", FieldThr: 1}
+	fm, _ := NewFieldsMap(table, &a)
+	fm.SetPriority(LowPriority)
+	fm.Set("field_one", "dirty")
+
+	b := DemoRow{FieldKey: "keyb", FieldThr: 2}
+	if err := fm.Rebind(&b); err != nil {
+		t.Fatal(err)
+	}
+	if fm.PrimaryKeyValue() != "keyb" || fm.GetFieldValue(3) != int64(2) {
+		t.Errorf("fields not rebound: %v", fm.GetFieldValues())
+	}
+	if len(fm.DirtyFields()) != 0 {
+		t.Error("dirty fields should be cleared by Rebind")
+	}
+
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, "UPDATE LOW_PRIORITY ") || q.args[5] != "keyb" {
+		t.Errorf("options should be kept, got %q %v", q.sql, q.args)
+	}
+
+	if err := fm.Rebind(&struct{ FieldKey string }{}); err == nil {
+		t.Error("want error for another struct type")
+	}
+	if err := fm.Rebind(nil); err == nil {
+		t.Error("want error for nil")
+	}
+	if err := fm.Rebind((*DemoRow)(nil)); err == nil {
+		t.Error("want error for nil pointer")
+	}
+}
+
+func TestSQLSelect(t *testing.T) {
+
+	all := demoRowsResult(5)
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		res := &fakeResult{cols: all.cols}
+		for _, r := range all.rows {
+			if r[3].(int64) > args[0].(int64) {
+				res.rows = append(res.rows, r)
+			}
+		}
+		return res
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	objs, err := fm.SQLSelect(context.Background(), nil, db, " where `field_thr` > ? ", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 || objs[0].(*DemoRow).FieldThr != 3 || objs[1].(*DemoRow).FieldThr != 4 {
+		t.Errorf("unexpected rows %v", objs)
+	}
+	q := fdb.LastQuery()
+	if q.sql != fm.SelectSQL(" where `field_thr` > ? ") || len(q.args) != 1 || q.args[0] != int64(2) {
+		t.Errorf("unexpected %q %v", q.sql, q.args)
+	}
+}
@@ -0,0 +1,415 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// SelectAll select rows into []T
+// T is the struct mapped to table, reflect metadata is parsed once per T,
+// rows are scanned into a single T then appended, no per-row reflect walk.
+// example:
+// rows, err := SelectAll[DemoRow](ctx, db, "test_table",
+// 	" where `field_thr` > ? ", 10)
+//
+func SelectAll[T any](ctx context.Context, exec Executor, table string,
+	extStr string, args ...interface{}) ([]T, error) {
+
+	return selectAll[T](ctx, exec, table, nil, extStr, args...)
+}
+
+// selectAll SelectAll with statement cache & logger of m, nil for none
+func selectAll[T any](ctx context.Context, exec Executor, table string,
+	m *Mapper[T], extStr string, args ...interface{}) ([]T, error) {
+
+	if err := checkTable(table); err != nil {
+		return nil, err
+	}
+
+	var obj T
+	layout, err := genericLayout(reflect.TypeOf(obj))
+	if err != nil {
+		return nil, err
+	}
+	fds := newFieldsMapFromLayout(table, &obj, layout)
+	m.apply(fds)
+
+	var zero T
+	addrs := fds.GetFieldSaveAddrs()
+	objs := []T{}
+	extStr, args = fds.scoped(extStr, args...)
+	err = fds.queryRows(ctx, exec, fds.selectSQL(extStr), args, func(rs *sql.Rows) error {
+		for rs.Next() {
+			obj = zero
+			err := scanRow(rs, addrs...)
+			if err != nil {
+				return err
+			}
+			_, err = fds.mapBack()
+			if err != nil {
+				return err
+			}
+			objs = append(objs, obj)
+		}
+
+		return rs.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return objs, nil
+}
+
+// SelectInto select rows into dst, at most len(dst) rows with no append,
+// so an array dst[:] or a preallocated slice is filled in place,
+// return number of rows scanned, ErrTooManyRows if result has more rows
+// example:
+// var rows [3]DemoRow
+// n, err := SelectInto(ctx, db, "test_table", rows[:], " where `field_two` = ? ", true)
+//
+func SelectInto[T any](ctx context.Context, exec Executor, table string,
+	dst []T, extStr string, args ...interface{}) (int, error) {
+
+	if err := checkTable(table); err != nil {
+		return 0, err
+	}
+
+	var obj T
+	layout, err := genericLayout(reflect.TypeOf(obj))
+	if err != nil {
+		return 0, err
+	}
+	fds := newFieldsMapFromLayout(table, &obj, layout)
+
+	var zero T
+	addrs := fds.GetFieldSaveAddrs()
+	n := 0
+	extStr, args = fds.scoped(extStr, args...)
+	err = fds.queryRows(ctx, exec, fds.selectSQL(extStr), args, func(rs *sql.Rows) error {
+		for rs.Next() {
+			if n >= len(dst) {
+				return ErrTooManyRows
+			}
+			obj = zero
+			err := scanRow(rs, addrs...)
+			if err != nil {
+				return err
+			}
+			_, err = fds.mapBack()
+			if err != nil {
+				return err
+			}
+			dst[n] = obj
+			n++
+		}
+
+		return rs.Err()
+	})
+	if err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+// SelectByPriKey select one row into *T by primary key,
+// *NotFoundError (ErrNotFound, wraps sql.ErrNoRows) if no row match,
+// error on composite primary key, key must be of primary key type
+// or an int (constant) for a signed integer primary key
+// example: row, err := SelectByPriKey[DemoRow](ctx, db, "test_table", "key001")
+func SelectByPriKey[T any](ctx context.Context, exec Executor, table string,
+	key interface{}) (*T, error) {
+
+	return selectByPriKey[T](ctx, exec, table, nil, key)
+}
+
+// selectByPriKey SelectByPriKey with statement cache & logger of m, nil for none
+func selectByPriKey[T any](ctx context.Context, exec Executor, table string,
+	m *Mapper[T], key interface{}) (*T, error) {
+
+	if err := checkTable(table); err != nil {
+		return nil, err
+	}
+
+	obj := new(T)
+	layout, err := genericLayout(reflect.TypeOf(obj).Elem())
+	if err != nil {
+		return nil, err
+	}
+	fds := newFieldsMapFromLayout(table, obj, layout)
+	m.apply(fds)
+	if len(fds.fields) == 0 {
+		return nil, errors.New("no field in " + layout.reftype.String())
+	}
+	if err := fds.singlePriKey(); err != nil {
+		return nil, err
+	}
+
+	pk := reflect.ValueOf(fds.fields[fds.pk].Addr).Elem()
+	kv, ok := assignValue(pk.Type(), key)
+	if !ok {
+		return nil, fmt.Errorf("key %T is not %s", key, pk.Type().String())
+	}
+	pk.Set(kv)
+
+	extStr, args := fds.scoped(" where "+fds.priKeyCond()+" ", fds.GetFieldValue(fds.pk))
+	_, err = fds.selectOne(ctx, exec, fds.selectSQL(extStr), args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &NotFoundError{Table: table, Type: layout.reftype.Name(), Key: key}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// SelectChan select rows and send each as T on the returned channel,
+// from a goroutine, rows are fetched as the channel is received,
+// so memory is bounded whatever the result size.
+// both channels are closed when rows are done, on error or ctx done,
+// at most one error (ctx.Err() if canceled) is sent before close,
+// the caller must receive until close or cancel ctx.
+// example:
+// rowCh, errCh := SelectChan[DemoRow](ctx, db, "test_table", " where `field_thr` > ? ", 10)
+// for row := range rowCh { ... }
+// if err := <-errCh; err != nil { ... }
+//
+func SelectChan[T any](ctx context.Context, exec Executor, table string,
+	extStr string, args ...interface{}) (<-chan T, <-chan error) {
+
+	rowCh := make(chan T)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(errCh)
+		defer close(rowCh)
+
+		if err := checkTable(table); err != nil {
+			errCh <- err
+			return
+		}
+
+		var obj T
+		layout, err := genericLayout(reflect.TypeOf(obj))
+		if err != nil {
+			errCh <- err
+			return
+		}
+		fds := newFieldsMapFromLayout(table, &obj, layout)
+
+		var zero T
+		addrs := fds.GetFieldSaveAddrs()
+		extStr, args := fds.scoped(extStr, args...)
+		err = fds.queryRows(ctx, exec, fds.selectSQL(extStr), args, func(rs *sql.Rows) error {
+			for rs.Next() {
+				obj = zero
+				err := scanRow(rs, addrs...)
+				if err != nil {
+					return err
+				}
+				_, err = fds.mapBack()
+				if err != nil {
+					return err
+				}
+
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				select {
+				case rowCh <- obj:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			return rs.Err()
+		})
+		if err != nil {
+			errCh <- err
+		}
+	}()
+
+	return rowCh, errCh
+}
+
+// genericLayout layout of T for the generic funcs & Mapper,
+// error if T can not be mapped or has vault fields, as the vault row
+// is written by FieldsMap after SetVault only
+func genericLayout(reftype reflect.Type) (*structLayout, error) {
+
+	layout, err := cachedStructLayout(reftype)
+	if err != nil {
+		return nil, err
+	}
+	if len(layout.vault) > 0 {
+		return nil, errors.New("vault fields of " + reftype.String() +
+			" need FieldsMap with SetVault")
+	}
+
+	return layout, nil
+}
+
+////////////////////////////////////////////////////////////////
+
+// Mapper typed access to a table for struct T,
+// results are T / *T instead of interface{} to cast,
+// safe for concurrent use, see NewMapperWithStmtCache to reuse statements
+// example:
+// m, err := NewMapper[DemoRow]("test_table")
+// row, err := m.SelectByPriKey(ctx, nil, db, "key001")
+// row.FieldThr++
+// err = m.Update(ctx, nil, db, row)
+//
+type Mapper[T any] struct {
+	table  string
+	stmts  *stmtCache // shared by all calls, nil if no cache
+	logger Logger     // nil if no logging
+}
+
+// NewMapper new Mapper of T on table, error if T can not be mapped
+// or has vault fields (see SetVault of FieldsMap)
+func NewMapper[T any](table string) (*Mapper[T], error) {
+
+	if err := checkTable(table); err != nil {
+		return nil, err
+	}
+
+	var obj T
+	_, err := genericLayout(reflect.TypeOf(obj))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Mapper[T]{table: table}, nil
+}
+
+// NewMapperWithStmtCache new Mapper of T on table keeping up to size
+// statements prepared on db (not tx) for reuse by all its calls,
+// least recently used are closed, Close closes the others.
+// see SetStmtCache of FieldsMap
+// example:
+// m, err := NewMapperWithStmtCache[DemoRow]("test_table", 64)
+// defer m.Close()
+//
+func NewMapperWithStmtCache[T any](table string, size int) (*Mapper[T], error) {
+
+	m, err := NewMapper[T](table)
+	if err != nil {
+		return nil, err
+	}
+
+	m.stmts = newStmtCache(size)
+	return m, nil
+}
+
+// StmtCacheStats counters of the statement cache of m, zero if none
+func (m *Mapper[T]) StmtCacheStats() StmtCacheStats {
+
+	return m.stmts.stats()
+}
+
+// Close close statements cached by m, statements in use are closed
+// when their call returns, m still works but prepares each call
+func (m *Mapper[T]) Close() error {
+
+	if m.stmts == nil {
+		return nil
+	}
+
+	return m.stmts.closeAll()
+}
+
+// SetLogger log statements executed by all calls of m to l,
+// nil to stop logging, set before m is used concurrently.
+// see SetLogger of FieldsMap
+func (m *Mapper[T]) SetLogger(l Logger) {
+
+	m.logger = l
+}
+
+// apply statement cache & logger of m to fds, nothing if m is nil
+func (m *Mapper[T]) apply(fds *_FieldsMap) {
+
+	if m == nil {
+		return
+	}
+
+	fds.stmts = m.stmts
+	fds.logger = m.logger
+}
+
+// fieldsMap FieldsMap of obj on table of m
+func (m *Mapper[T]) fieldsMap(obj *T) (*_FieldsMap, error) {
+
+	layout, err := genericLayout(reflect.TypeOf(obj).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	fds := newFieldsMapFromLayout(m.table, obj, layout)
+	m.apply(fds)
+	return fds, nil
+}
+
+// SelectByPriKey select one row by primary key,
+// *NotFoundError (ErrNotFound, wraps sql.ErrNoRows) if no row match
+func (m *Mapper[T]) SelectByPriKey(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	key interface{}) (*T, error) {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectByPriKey[T](ctx, exec, m.table, m, key)
+}
+
+// SelectAll select rows by condition in extStr, args bind to extStr
+func (m *Mapper[T]) SelectAll(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	extStr string, args ...interface{}) ([]T, error) {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectAll[T](ctx, exec, m.table, m, extStr, args...)
+}
+
+// Insert insert obj
+func (m *Mapper[T]) Insert(ctx context.Context, tx *sql.Tx, db *sql.DB, obj *T) error {
+
+	fds, err := m.fieldsMap(obj)
+	if err != nil {
+		return err
+	}
+
+	return fds.SQLInsert(ctx, tx, db)
+}
+
+// Update update obj by primary key
+func (m *Mapper[T]) Update(ctx context.Context, tx *sql.Tx, db *sql.DB, obj *T) error {
+
+	fds, err := m.fieldsMap(obj)
+	if err != nil {
+		return err
+	}
+
+	return fds.SQLUpdateByPriKey(ctx, tx, db)
+}
+
+// Delete delete obj by primary key
+func (m *Mapper[T]) Delete(ctx context.Context, tx *sql.Tx, db *sql.DB, obj *T) error {
+
+	fds, err := m.fieldsMap(obj)
+	if err != nil {
+		return err
+	}
+
+	return fds.SQLDeleteByPriKey(ctx, tx, db)
+}
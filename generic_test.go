@@ -0,0 +1,447 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSelectAll(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return demoRowsResult(3)
+	})
+	defer db.Close()
+
+	rows, err := SelectAll[DemoRow](context.Background(), db, table,
+		" where `field_thr` >= ? ", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	if rows[0].FieldKey != "keya" || rows[2].FieldThr != 2 || !rows[2].FieldTwo {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+
+	want := "SELECT  `field_key`, `field_one`, `field_two`, `field_thr`, `field_fou`  " +
+		"FROM `test_table`  where `field_thr` >= ? "
+	if q := fdb.LastQuery(); q.sql != want || len(q.args) != 1 {
+		t.Errorf("got %q %v, want %q", q.sql, q.args, want)
+	}
+}
+
+func TestSelectAllEmpty(t *testing.T) {
+
+	db, _ := newFakeDB(nil)
+	defer db.Close()
+
+	rows, err := SelectAll[DemoRow](context.Background(), db, table, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows == nil || len(rows) != 0 {
+		t.Errorf("want empty non-nil slice, got %#v", rows)
+	}
+}
+
+func TestSelectInto(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return demoRowsResult(3)
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var rows [4]DemoRow
+	n, err := SelectInto(ctx, db, table, rows[:], "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || rows[2].FieldKey != "keyc" || rows[3].FieldKey != "" {
+		t.Errorf("got %d rows: %+v", n, rows)
+	}
+
+	small := make([]DemoRow, 2)
+	n, err = SelectInto(ctx, db, table, small, "")
+	if !errors.Is(err, ErrTooManyRows) || n != 2 {
+		t.Errorf("got %d, %v, want 2, ErrTooManyRows", n, err)
+	}
+}
+
+func TestSelectAllUnsupported(t *testing.T) {
+
+	db, _ := newFakeDB(nil)
+	defer db.Close()
+
+	if _, err := SelectAll[int](context.Background(), db, table, ""); err == nil {
+		t.Error("want error for non-struct T")
+	}
+}
+
+func BenchmarkSelectAllGeneric(b *testing.B) {
+
+	res := demoRowsResult(1000)
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := SelectAll[DemoRow](ctx, db, table, ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSelectAllInterface(b *testing.B) {
+
+	res := demoRowsResult(1000)
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := QueryAll(ctx, nil, db); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSelectByPriKey(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if args[0] == "key001" {
+			return demoRowsResult(1)
+		}
+		return &fakeResult{cols: demoRowsResult(0).cols}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row, err := SelectByPriKey[DemoRow](ctx, db, table, "key001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.FieldKey != "keya" {
+		t.Errorf("unexpected row %+v", row)
+	}
+	if q := fdb.LastQuery(); !strings.HasSuffix(q.sql, "where `field_key` = ? ") {
+		t.Errorf("unexpected sql %q", q.sql)
+	}
+
+	_, err = SelectByPriKey[DemoRow](ctx, db, table, "key404")
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("got %v, want sql.ErrNoRows", err)
+	}
+	var nf *NotFoundError
+	if !errors.As(err, &nf) || nf.Key != "key404" || nf.Table != table {
+		t.Errorf("got %#v", err)
+	}
+	if err.Error() != "test_table: DemoRow with key key404 not found" {
+		t.Errorf("message %q", err.Error())
+	}
+
+	if _, err := SelectByPriKey[DemoRow](ctx, db, table, 1); err == nil {
+		t.Error("want error for key of wrong type")
+	}
+}
+
+func TestSelectByPriKeyInt(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{cols: []string{"id", "name", "deleted_at"},
+			rows: [][]driver.Value{{args[0], "ann", nil}}}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	// untyped int constant for int64 primary key, as SetScope & Set
+	m, err := NewMapper[trashRow]("trash_table")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row, err := m.SelectByPriKey(ctx, nil, db, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.ID != 42 || row.Name != "ann" {
+		t.Errorf("unexpected row %+v", row)
+	}
+	if q := fdb.LastQuery(); q.args[0] != int64(42) {
+		t.Errorf("got args %v", q.args)
+	}
+
+	if _, err := m.SelectByPriKey(ctx, nil, db, "42"); err == nil {
+		t.Error("want error for string key of int64 primary key")
+	}
+	if _, err := m.SelectByPriKey(ctx, nil, db, 42.0); err == nil {
+		t.Error("want error for float key of int64 primary key")
+	}
+	if _, err := m.SelectByPriKey(ctx, nil, db, nil); err == nil {
+		t.Error("want error for nil key")
+	}
+}
+
+func TestSelectChan(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return demoRowsResult(5)
+	})
+	defer db.Close()
+
+	rowCh, errCh := SelectChan[DemoRow](context.Background(), db, table, "")
+	var rows []DemoRow
+	for row := range rowCh {
+		rows = append(rows, row)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 5 || rows[1].FieldKey != "keyb" || rows[4].FieldThr != 4 {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rowCh, errCh = SelectChan[DemoRow](ctx, db, table, "")
+	<-rowCh
+	cancel()
+	for range rowCh {
+	}
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Errorf("want context.Canceled, got %v", err)
+	}
+
+	_, errCh = SelectChan[struct{ C chan int }](context.Background(), db, table, "")
+	if err := <-errCh; err == nil {
+		t.Error("want error for unsupported type")
+	}
+}
+
+func TestMapper(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return demoRowsResult(2)
+		}
+		return nil
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	m, err := NewMapper[DemoRow](table)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// QueryByKey & QueryAll without casts
+	row, err := m.SelectByPriKey(ctx, nil, db, "keya")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.FieldKey != "keya" || row.FieldOne != "one" {
+		t.Errorf("unexpected row %+v", row)
+	}
+	rows, err := m.SelectAll(ctx, nil, db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1].FieldKey != "keyb" {
+		t.Errorf("unexpected rows %+v", rows)
+	}
+
+	row.FieldThr = 1234
+	if err := m.Update(ctx, nil, db, row); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, "UPDATE `test_table`") || q.args[3] != int64(1234) {
+		t.Errorf("update got %q %v", q.sql, q.args)
+	}
+
+	if err := m.Insert(ctx, nil, db, &DemoRow{FieldKey: "key002"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete(ctx, nil, db, &DemoRow{FieldKey: "key001"}); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, "DELETE") || q.args[0] != "key001" {
+		t.Errorf("delete got %q %v", q.sql, q.args)
+	}
+
+	if _, err := NewMapper[int](table); err == nil {
+		t.Error("want error for non-struct T")
+	}
+}
+
+func TestMapperStmtCache(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return demoRowsResult(1)
+		}
+		return nil
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	m, err := NewMapperWithStmtCache[DemoRow](table, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		row, err := m.SelectByPriKey(ctx, nil, db, "keya")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a new FieldsMap each call shares the statements of m
+		if err := m.Update(ctx, nil, db, row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(fdb.Prepares()); n != 2 {
+		t.Errorf("got %d prepares, want 2", n)
+	}
+	if s := m.StmtCacheStats(); s.Hits != 4 || s.Misses != 2 || s.Size != 2 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if n := len(fdb.Closes()); n != 0 {
+		t.Errorf("cached statements closed before Close: %d", n)
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := fdb.Closes(); len(got) != 2 {
+		t.Errorf("Close should close cached statements, closed %v", got)
+	}
+	if s := m.StmtCacheStats(); s.Size != 0 {
+		t.Errorf("cache should be empty after Close, got %+v", s)
+	}
+
+	if _, err := m.SelectByPriKey(ctx, nil, db, "keya"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fdb.Closes(); len(got) != 3 {
+		t.Errorf("statements after Close should not be cached, closed %v", got)
+	}
+}
+
+func TestMapperSoftDelete(t *testing.T) {
+
+	// one row, the fake driver applies the IS NULL filter
+	var deletedAt driver.Value
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "UPDATE") {
+			deletedAt = args[0]
+			return &fakeResult{affected: 1}
+		}
+		res := &fakeResult{cols: []string{"id", "name", "deleted_at"}}
+		if deletedAt == nil || !strings.Contains(q, "IS NULL") {
+			res.rows = [][]driver.Value{{int64(1), "row", deletedAt}}
+		}
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	m, _ := NewMapper[trashRow]("trash_table")
+	row, err := m.SelectByPriKey(ctx, nil, db, int64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete(ctx, nil, db, row); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, "UPDATE `trash_table` SET `deleted_at` = ?") {
+		t.Fatalf("want soft delete, got %q", q.sql)
+	}
+
+	if _, err := m.SelectByPriKey(ctx, nil, db, int64(1)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("trashed row: want ErrNotFound, got %v", err)
+	}
+	if rows, err := m.SelectAll(ctx, nil, db, ""); err != nil || len(rows) != 0 {
+		t.Errorf("trashed row listed: %v %v", rows, err)
+	}
+	var dst [1]trashRow
+	if n, err := SelectInto(ctx, db, "trash_table", dst[:], ""); err != nil || n != 0 {
+		t.Errorf("SelectInto got trashed row: %d %v", n, err)
+	}
+	rowCh, errCh := SelectChan[trashRow](ctx, db, "trash_table", "")
+	for r := range rowCh {
+		t.Errorf("SelectChan got trashed row %+v", r)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT  `id`, `name`, `deleted_at`  FROM `trash_table`  where `trash_table`.`deleted_at` IS NULL "
+	if q := fdb.LastQuery(); q.sql != want {
+		t.Errorf("got %q\nwant %q", q.sql, want)
+	}
+}
+
+func TestMapperSetLogger(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return demoRowsResult(1)
+		}
+		return nil
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	for _, size := range []int{0, 8} {
+		m, err := NewMapperWithStmtCache[DemoRow](table, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l := &testLogger{}
+		m.SetLogger(l)
+
+		row, err := m.SelectByPriKey(ctx, nil, db, "keya")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.SelectAll(ctx, nil, db, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Insert(ctx, nil, db, &DemoRow{FieldKey: "key002"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Update(ctx, nil, db, row); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Delete(ctx, nil, db, row); err != nil {
+			t.Fatal(err)
+		}
+
+		wants := []string{"SELECT", "SELECT", "INSERT", "UPDATE", "DELETE"}
+		if len(l.entries) != len(wants) {
+			t.Fatalf("cache %d: got %d entries, want %d", size, len(l.entries), len(wants))
+		}
+		for i, want := range wants {
+			if !strings.HasPrefix(l.entries[i].sql, want) {
+				t.Errorf("cache %d: entry %d got %q, want %s", size, i, l.entries[i].sql, want)
+			}
+		}
+
+		m.SetLogger(nil)
+		m.SelectAll(ctx, nil, db, "")
+		if len(l.entries) != len(wants) {
+			t.Errorf("cache %d: nil logger should stop logging", size)
+		}
+		m.Close()
+	}
+}
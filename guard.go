@@ -0,0 +1,54 @@
+package sqlmapper
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultMaxSQLBytes default SQLGuard.MaxBytes, 1MB
+	DefaultMaxSQLBytes = 1 << 20
+	// DefaultMaxPlaceholders default SQLGuard.MaxPlaceholders,
+	// placeholder limit of a MySQL prepared statement
+	DefaultMaxPlaceholders = 65535
+)
+
+// SQLGuard limits checked before a statement is prepared,
+// zero value of a limit is its default
+type SQLGuard struct {
+	MaxBytes        int
+	MaxPlaceholders int
+}
+
+// SetSQLGuard set limits of statements prepared by fds,
+// ErrSQLTooLarge if a statement exceeds them, no limit until set
+// example: fds.SetSQLGuard(sqlmapper.SQLGuard{MaxPlaceholders: 10000})
+func (fds *_FieldsMap) SetSQLGuard(guard SQLGuard) {
+
+	if guard.MaxBytes <= 0 {
+		guard.MaxBytes = DefaultMaxSQLBytes
+	}
+	if guard.MaxPlaceholders <= 0 {
+		guard.MaxPlaceholders = DefaultMaxPlaceholders
+	}
+	fds.guard = &guard
+}
+
+// checkGuard check sqlstr against SQLGuard
+func (fds *_FieldsMap) checkGuard(sqlstr string) error {
+
+	if fds.guard == nil {
+		return nil
+	}
+
+	if len(sqlstr) > fds.guard.MaxBytes {
+		return fmt.Errorf("%w: %d bytes, max %d", ErrSQLTooLarge,
+			len(sqlstr), fds.guard.MaxBytes)
+	}
+	if n := strings.Count(sqlstr, "?"); n > fds.guard.MaxPlaceholders {
+		return fmt.Errorf("%w: %d placeholders, max %d", ErrSQLTooLarge,
+			n, fds.guard.MaxPlaceholders)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package sqlmapper
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSetSQLGuard(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	keys := make([]interface{}, 20)
+	for i := range keys {
+		keys[i] = "key"
+	}
+	if _, err := fm.SQLSelectByPriKeys(ctx, nil, db, keys); err != nil {
+		t.Fatalf("no guard until set, got %v", err)
+	}
+
+	fm.SetSQLGuard(SQLGuard{MaxPlaceholders: 10})
+	n := len(fdb.Prepares())
+	_, err := fm.SQLSelectByPriKeys(ctx, nil, db, keys)
+	if !errors.Is(err, ErrSQLTooLarge) {
+		t.Errorf("got %v, want ErrSQLTooLarge", err)
+	}
+	if len(fdb.Prepares()) != n {
+		t.Error("statement over limit should not be prepared")
+	}
+
+	fm.SetSQLGuard(SQLGuard{MaxBytes: 64})
+	if _, err := fm.SQLSelectAllRows(ctx, nil, db); !errors.Is(err, ErrSQLTooLarge) {
+		t.Errorf("got %v, want ErrSQLTooLarge", err)
+	}
+	if err := fm.SQLDeleteByPriKey(ctx, nil, db); err != nil {
+		t.Errorf("short statement got %v", err)
+	}
+}
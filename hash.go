@@ -0,0 +1,64 @@
+package sqlmapper
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// hashIndex index of field with `hash` tag option, -1 if none
+func (fds *_FieldsMap) hashIndex() int {
+
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if fds.fields[i].hash {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// rowHash FNV-1a of tags & values of all fields but the hash field
+func (fds *_FieldsMap) rowHash() int64 {
+
+	h := fnv.New64a()
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if fds.fields[i].hash || fds.fields[i].version || len(fds.fields[i].expr) > 0 {
+			continue
+		}
+
+		value := fds.GetFieldValue(i)
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC().Format(time.RFC3339Nano)
+		}
+		fmt.Fprintf(h, "%s=%v\x00", fds.fields[i].Tag, value)
+	}
+
+	return int64(h.Sum64())
+}
+
+// fillRowHash set hash field to hash of current values
+func (fds *_FieldsMap) fillRowHash() {
+
+	if idx := fds.hashIndex(); idx >= 0 {
+		*fds.fields[idx].Addr.(*int64) = fds.rowHash()
+	}
+}
+
+// RowHashChanged values differ from hash field, i.e. Object(struct)
+// changed since read from db or last write, always true without hash field
+// example:
+// type SyncRow struct {
+// 	ID   string `sql:"id"`
+// 	Name string `sql:"name"`
+// 	Hash int64  `sql:"row_hash,hash"`
+// }
+func (fds *_FieldsMap) RowHashChanged() bool {
+
+	idx := fds.hashIndex()
+	if idx < 0 {
+		return true
+	}
+
+	return *fds.fields[idx].Addr.(*int64) != fds.rowHash()
+}
@@ -0,0 +1,104 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+type syncRow struct {
+	ID   string `sql:"id"`
+	Name string `sql:"name"`
+	Hash int64  `sql:"row_hash,hash"`
+}
+
+func TestRowHash(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	row := syncRow{ID: "id001", Name: "one"}
+	fm, err := NewFieldsMap("sync_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fm.RowHashChanged() {
+		t.Error("new row should be changed")
+	}
+
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if row.Hash == 0 || fdb.LastQuery().args[2] != row.Hash {
+		t.Errorf("hash not filled before insert: %v", fdb.LastQuery().args)
+	}
+	if fm.RowHashChanged() {
+		t.Error("row should be unchanged after insert")
+	}
+
+	n := len(fdb.Queries())
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if len(fdb.Queries()) != n {
+		t.Error("unchanged row should not be updated")
+	}
+
+	inserted := row.Hash
+	row.Name = "two"
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if len(fdb.Queries()) != n+1 || row.Hash == inserted {
+		t.Errorf("changed row should be updated with new hash %d", row.Hash)
+	}
+}
+
+func TestRowHashRead(t *testing.T) {
+
+	stored := syncRow{ID: "id001", Name: "one"}
+	sfm, _ := NewFieldsMap("sync_table", &stored)
+	sfm.(*_FieldsMap).fillRowHash()
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "name", "row_hash"},
+			rows: [][]driver.Value{{stored.ID, stored.Name, stored.Hash}},
+		}
+	})
+	defer db.Close()
+
+	row := syncRow{ID: "id001"}
+	fm, _ := NewFieldsMap("sync_table", &row)
+	if _, err := fm.SQLSelectByPriKey(context.Background(), nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if fm.RowHashChanged() {
+		t.Error("row read from db should be unchanged")
+	}
+	row.Name = "two"
+	if !fm.RowHashChanged() {
+		t.Error("modified row should be changed")
+	}
+}
+
+func TestRowHashInvalid(t *testing.T) {
+
+	type strHash struct {
+		ID   string `sql:"id"`
+		Hash string `sql:"row_hash,hash"`
+	}
+	if _, err := NewFieldsMap("sync_table", &strHash{}); err == nil {
+		t.Error("want error for hash on string")
+	}
+
+	type twoHash struct {
+		ID string `sql:"id"`
+		H1 int64  `sql:"h1,hash"`
+		H2 int64  `sql:"h2,hash"`
+	}
+	if _, err := NewFieldsMap("sync_table", &twoHash{}); err == nil {
+		t.Error("want error for two hash fields")
+	}
+}
@@ -0,0 +1,86 @@
+package sqlmapper
+
+import (
+	"context"
+)
+
+// BeforeInserter Object(struct) called before SQLInsert,
+// an error aborts the insert
+type BeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// AfterInserter Object(struct) called after a successful SQLInsert
+type AfterInserter interface {
+	AfterInsert(ctx context.Context) error
+}
+
+// BeforeUpdater Object(struct) called before SQLUpdateByPriKey,
+// an error aborts the update
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// AfterUpdater Object(struct) called after a successful SQLUpdateByPriKey
+type AfterUpdater interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+// BeforeDeleter Object(struct) called before SQLDeleteByPriKey,
+// an error aborts the delete
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+// AfterDeleter Object(struct) called after a successful SQLDeleteByPriKey
+type AfterDeleter interface {
+	AfterDelete(ctx context.Context) error
+}
+
+// hook lifecycle point of a write by primary key
+type hook int
+
+const (
+	hookBeforeInsert hook = iota
+	hookAfterInsert
+	hookBeforeUpdate
+	hookAfterUpdate
+	hookBeforeDelete
+	hookAfterDelete
+)
+
+// callHook call hook h of Object(struct) if it implements it,
+// hooks are called by SQLInsert, SQLUpdateByPriKey, SQLDeleteByPriKey
+// and their *Result / *On forms, not by batch or conditional writes
+func (fds *_FieldsMap) callHook(ctx context.Context, h hook) error {
+
+	switch h {
+	case hookBeforeInsert:
+		if o, ok := fds.objptr.(BeforeInserter); ok {
+			return o.BeforeInsert(ctx)
+		}
+	case hookAfterInsert:
+		if o, ok := fds.objptr.(AfterInserter); ok {
+			return o.AfterInsert(ctx)
+		}
+	case hookBeforeUpdate:
+		if o, ok := fds.objptr.(BeforeUpdater); ok {
+			return o.BeforeUpdate(ctx)
+		}
+	case hookAfterUpdate:
+		if o, ok := fds.objptr.(AfterUpdater); ok {
+			return o.AfterUpdate(ctx)
+		}
+	case hookBeforeDelete:
+		if o, ok := fds.objptr.(BeforeDeleter); ok {
+			return o.BeforeDelete(ctx)
+		}
+	case hookAfterDelete:
+		if o, ok := fds.objptr.(AfterDeleter); ok {
+			return o.AfterDelete(ctx)
+		}
+	default:
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package sqlmapper
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type hookedRow struct {
+	ID     int64  `sql:"id"`
+	Name   string `sql:"name"`
+	Status string `sql:"status"`
+
+	calls []string
+}
+
+func (r *hookedRow) BeforeInsert(ctx context.Context) error {
+
+	r.calls = append(r.calls, "before insert")
+	if len(r.Name) == 0 {
+		return errors.New("name required")
+	}
+	r.Status = "new"
+	return nil
+}
+
+func (r *hookedRow) AfterInsert(ctx context.Context) error {
+
+	r.calls = append(r.calls, "after insert")
+	return nil
+}
+
+func (r *hookedRow) BeforeDelete(ctx context.Context) error {
+
+	r.calls = append(r.calls, "before delete")
+	return nil
+}
+
+func TestWriteHooks(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	row := hookedRow{ID: 1}
+	fm, err := NewFieldsMap("hooked_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fm.SQLInsert(ctx, nil, db); err == nil || err.Error() != "name required" {
+		t.Errorf("want BeforeInsert error, got %v", err)
+	}
+	if n := len(fdb.Queries()); n != 0 {
+		t.Errorf("aborted insert ran %d queries", n)
+	}
+
+	row.Name = "ann"
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); len(q.args) != 3 || q.args[2] != "new" {
+		t.Errorf("BeforeInsert field not bound: %v", q.args)
+	}
+
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if err := fm.SQLDeleteByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"before insert", "before insert", "after insert", "before delete"}
+	if len(row.calls) != len(want) {
+		t.Fatalf("got calls %v, want %v", row.calls, want)
+	}
+	for i := range want {
+		if row.calls[i] != want[i] {
+			t.Errorf("got calls %v, want %v", row.calls, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// SQLIncrementByPriKey add delta to column nameInDB of the row of primary key
+// in one atomic statement, negative delta to decrement,
+// Object(struct) is not changed, return rows affected
+// example: fds.SQLIncrementByPriKey(ctx, tx, db, "views", 1)
+// UPDATE `t` SET `views` = `views` + ? where `id` = ?
+func (fds *_FieldsMap) SQLIncrementByPriKey(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	nameInDB string, delta int64) (int64, error) {
+
+	idx := fds.fieldIndex(nameInDB)
+	if idx < 0 {
+		return 0, errors.New("no field match `sql` tag:" + nameInDB)
+	}
+	switch baseType(fds.fields[idx].Type) {
+	case "int64", "uint64", "float64":
+	default:
+		return 0, errors.New("increment of non numeric field:" + nameInDB)
+	}
+	if fds.isPriKey(idx) || len(fds.fields[idx].expr) > 0 {
+		return 0, errors.New("primary key or select-only field can not be incremented:" + nameInDB)
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	col := fds.quote(fds.fields[idx].Tag)
+	extStr, args := fds.scoped(" where "+fds.priKeyCond()+" ", fds.priKeyValues()...)
+	sqlstr := fds.verb("UPDATE") + fds.quoteTable() + " SET " + col + " = " + col + " + ?" + extStr
+	res, err := fds.execSQL(ctx, exec, sqlstr, append([]interface{}{delta}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
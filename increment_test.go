@@ -0,0 +1,55 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+)
+
+func TestSQLIncrementByPriKey(t *testing.T) {
+
+	var mu sync.Mutex
+	total := int64(0)
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		mu.Lock()
+		defer mu.Unlock()
+		total += args[0].(int64)
+		return &fakeResult{affected: 1}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "keya"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	var wg sync.WaitGroup
+	want := int64(0)
+	for i := 0; i < 50; i++ {
+		delta := int64(i%7 - 2)
+		want += delta
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := fm.SQLIncrementByPriKey(ctx, nil, db, "field_thr", delta)
+			if err != nil || n != 1 {
+				t.Errorf("got %d %v", n, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if total != want {
+		t.Errorf("got total %d, want %d", total, want)
+	}
+	if q := fdb.LastQuery(); q.sql != "UPDATE `test_table` SET `field_thr` = `field_thr` + ? where `field_key` = ? " {
+		t.Errorf("unexpected sql %q", q.sql)
+	}
+
+	if _, err := fm.SQLIncrementByPriKey(ctx, nil, db, "field_one", 1); err == nil {
+		t.Error("want error for string field")
+	}
+	if _, err := fm.SQLIncrementByPriKey(ctx, nil, db, "nope", 1); err == nil {
+		t.Error("want error for unknown field")
+	}
+}
@@ -0,0 +1,285 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// DefaultKeysInThreshold number of keys above which SQLSelectByPriKeys
+// joins a temporary table instead of IN (?, ...)
+const DefaultKeysInThreshold = 1000
+
+// keysTempTable temporary table of keys for SQLSelectByPriKeys
+const keysTempTable = "sqlmapper_keys"
+
+// SetKeysInThreshold set number of keys above which SQLSelectByPriKeys
+// joins a temporary table, DefaultKeysInThreshold if n <= 0
+func (fds *_FieldsMap) SetKeysInThreshold(n int) {
+
+	fds.keysInThreshold = n
+}
+
+// SQLSelectByPriKeys select rows by primary keys, in no order,
+// with IN (?, ...) if keys are not more than threshold (SetKeysInThreshold),
+// else keys are inserted in batches into a temporary table joined in one tx,
+// a new tx is used when tx is nil, temporary table is dropped after select,
+// error on composite primary key
+// example: objs, err := fds.SQLSelectByPriKeys(ctx, nil, db,
+// 	[]interface{}{"key001", "key002"})
+func (fds *_FieldsMap) SQLSelectByPriKeys(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, keys []interface{}) ([]interface{}, error) {
+
+	if err := fds.singlePriKey(); err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return []interface{}{}, nil
+	}
+
+	threshold := fds.keysInThreshold
+	if threshold <= 0 {
+		threshold = DefaultKeysInThreshold
+	}
+
+	if len(keys) <= threshold {
+		exec, err := getExecutor(tx, db)
+		if err != nil {
+			return nil, err
+		}
+
+		extStr, args := fds.scoped(" where "+fds.quote(fds.fields[fds.pk].Tag)+" IN ("+placeholders(len(keys))+") ", keys...)
+		return fds.selectRows(ctx, exec, scanByPosition, fds.selectSQL(extStr), args...)
+	}
+
+	var objs []interface{}
+	err := withTx(ctx, tx, db, func(tx *sql.Tx) error {
+		var err error
+		objs, err = fds.selectByKeysTemp(ctx, tx, keys, threshold)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return objs, nil
+}
+
+// SQLSelectMapByPriKeys SQLSelectByPriKeys indexed by primary key,
+// map keys are PrimaryKeyValue of each row (int64 for int fields),
+// keys matching no row are absent from the map
+// example: m, err := fds.SQLSelectMapByPriKeys(ctx, nil, db,
+// 	[]interface{}{"key001", "key002"})
+// row, ok := m["key001"].(*Row)
+func (fds *_FieldsMap) SQLSelectMapByPriKeys(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, keys []interface{}) (map[interface{}]interface{}, error) {
+
+	objs, err := fds.SQLSelectByPriKeys(ctx, tx, db, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[interface{}]interface{}, len(objs))
+	for i, olen := 0, len(objs); i < olen; i++ {
+		rowMap, err := fds.newRowMap(objs[i])
+		if err != nil {
+			return nil, err
+		}
+		m[rowMap.PrimaryKeyValue()] = objs[i]
+	}
+
+	return m, nil
+}
+
+// SQLDeleteByPriKeys delete rows of primary keys by one
+// DELETE ... where `pk` IN (?, ...), rows of a struct with softdelete field
+// are trashed by UPDATE instead, hooks are not called,
+// return rows affected, 0 without query if no keys,
+// error on composite primary key or vault fields
+// example: n, err := fds.SQLDeleteByPriKeys(ctx, tx, db, []interface{}{"key001", "key002"})
+func (fds *_FieldsMap) SQLDeleteByPriKeys(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, keys []interface{}) (int64, error) {
+
+	if err := fds.singlePriKey(); err != nil {
+		return 0, err
+	}
+	if err := fds.noVault("SQLDeleteByPriKey"); err != nil {
+		return 0, err
+	}
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	inStr := " where " + fds.quote(fds.fields[fds.pk].Tag) + " IN (" + placeholders(len(keys)) + ") "
+	var sqlstr string
+	var args []interface{}
+	if idx := fds.softDeleteIndex(); idx >= 0 {
+		now := time.Now()
+		if fds.now != nil {
+			now = fds.now()
+		}
+		var extStr string
+		extStr, args = fds.scoped(inStr, keys...)
+		args = append([]interface{}{fds.bindValue(idx, now)}, args...)
+		sqlstr = fds.verb("UPDATE") + fds.quoteTable() + " SET " +
+			fds.quote(fds.fields[idx].Tag) + " = ?" + extStr
+	} else {
+		var extStr string
+		extStr, args = fds.scopedBy(inStr, false, keys...)
+		sqlstr = fds.deleteSQL(extStr)
+	}
+
+	res, err := fds.execSQL(ctx, exec, sqlstr, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// SQLUpdateFieldsByPriKeys set columns cols (by `sql` tag) to the values
+// in Object(struct) on rows of primary keys, by one
+// UPDATE ... SET `col` = ?, ... where `pk` IN (?, ...),
+// return rows affected, 0 without query if no keys,
+// error if a column matches no field or is primary key or select-only
+// example: row.Status = "processed"
+// n, err := fds.SQLUpdateFieldsByPriKeys(ctx, tx, db, []string{"status"}, keys)
+func (fds *_FieldsMap) SQLUpdateFieldsByPriKeys(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, cols []string, keys []interface{}) (int64, error) {
+
+	if err := fds.singlePriKey(); err != nil {
+		return 0, err
+	}
+	if len(cols) == 0 {
+		return 0, errors.New("no column to update")
+	}
+
+	var sets string
+	var values []interface{}
+	for i, clen := 0, len(cols); i < clen; i++ {
+		idx := fds.fieldIndex(cols[i])
+		if idx < 0 {
+			return 0, errors.New("no field match `sql` tag:" + cols[i])
+		}
+		if fds.isPriKey(idx) || len(fds.fields[idx].expr) > 0 {
+			return 0, errors.New("primary key or select-only field can not be updated:" + cols[i])
+		}
+		if len(sets) > 0 {
+			sets += ", "
+		}
+		sets += fds.quote(fds.fields[idx].Tag) + " = ?"
+		values = append(values, fds.GetFieldValue(idx))
+	}
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	extStr, args := fds.scoped(" where "+fds.quote(fds.fields[fds.pk].Tag)+" IN ("+placeholders(len(keys))+") ", keys...)
+	sqlstr := fds.verb("UPDATE") + fds.quoteTable() + " SET " + sets + extStr
+	res, err := fds.execSQL(ctx, exec, sqlstr, append(values, args...)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// selectByKeysTemp select rows joined with temporary table of keys,
+// keys are deduplicated (as IN does) and inserted batchSize a time
+func (fds *_FieldsMap) selectByKeysTemp(ctx context.Context, tx *sql.Tx,
+	keys []interface{}, batchSize int) ([]interface{}, error) {
+
+	keys = uniqueKeys(keys)
+	drop := "DROP TEMPORARY TABLE "
+	if _, pg := fds.dialect.(postgresDialect); pg {
+		drop = "DROP TABLE "
+	}
+
+	temp, tempKey := fds.quote(keysTempTable), fds.quote(keysTempTable+"_key")
+	sqlstr := "CREATE TEMPORARY TABLE " + temp + " (" + tempKey + " " + fds.columnType(fds.pk) + ")"
+	_, err := fds.execSQL(ctx, tx, sqlstr)
+	if err != nil {
+		return nil, err
+	}
+	// temporary table outlives tx rollback, drop it on the connection anyway
+	defer fds.execSQL(ctx, tx, drop+temp)
+
+	for start, klen := 0, len(keys); start < klen; start += batchSize {
+		end := start + batchSize
+		if end > klen {
+			end = klen
+		}
+
+		sqlstr := "INSERT INTO " + temp + " (" + tempKey + ") VALUES (?)"
+		for i := start + 1; i < end; i++ {
+			sqlstr += ", (?)"
+		}
+		_, err = fds.execSQL(ctx, tx, sqlstr, keys[start:end]...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	extStr, args := fds.scoped(" JOIN " + temp + " ON " + tempKey + " = " +
+		fds.quote(fds.fields[fds.pk].Tag) + " ")
+	return fds.selectRows(ctx, tx, scanByPosition, fds.selectSQL(extStr), args...)
+}
+
+// uniqueKeys keys without repeats in first seen order, keys are compared
+// as bound (e.g. int 1 repeats int64 1), keys of incomparable types are kept
+func uniqueKeys(keys []interface{}) []interface{} {
+
+	seen := make(map[interface{}]bool, len(keys))
+	uniq := make([]interface{}, 0, len(keys))
+	for i, klen := 0, len(keys); i < klen; i++ {
+		k := keys[i]
+		if v, err := driver.DefaultParameterConverter.ConvertValue(k); err == nil {
+			k = v
+		}
+		if b, ok := k.([]byte); ok {
+			k = string(b)
+		}
+		if k != nil && !reflect.TypeOf(k).Comparable() {
+			uniq = append(uniq, keys[i])
+			continue
+		}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		uniq = append(uniq, keys[i])
+	}
+
+	return uniq
+}
+
+// placeholders n comma separated ?
+// example: placeholders(3) returns "?, ?, ?"
+func placeholders(n int) string {
+
+	var vs string
+	for i := 0; i < n; i++ {
+		if len(vs) > 0 {
+			vs += ", "
+		}
+		vs += "?"
+	}
+
+	return vs
+}
@@ -0,0 +1,281 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSQLSelectByPriKeysIn(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return demoRowsResult(2)
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	objs, err := fm.SQLSelectByPriKeys(context.Background(), nil, db,
+		[]interface{}{"keya", "keyb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 {
+		t.Errorf("got %d rows, want 2", len(objs))
+	}
+	q := fdb.LastQuery()
+	if !strings.HasSuffix(q.sql, " where `field_key` IN (?, ?) ") || len(q.args) != 2 {
+		t.Errorf("unexpected %q %v", q.sql, q.args)
+	}
+
+	objs, err = fm.SQLSelectByPriKeys(context.Background(), nil, db, nil)
+	if err != nil || len(objs) != 0 {
+		t.Errorf("empty keys got %v %v", objs, err)
+	}
+}
+
+func TestSQLSelectByPriKeysTempTable(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return demoRowsResult(5)
+		}
+		return nil
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+	fm.SetKeysInThreshold(2)
+
+	keys := []interface{}{"keya", "keyb", "keyc", "keyd", "keye"}
+	objs, err := fm.SQLSelectByPriKeys(context.Background(), nil, db, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 5 {
+		t.Errorf("got %d rows, want 5", len(objs))
+	}
+
+	var got []string
+	for _, q := range fdb.Queries() {
+		got = append(got, q.sql)
+	}
+	want := []string{
+		"BEGIN",
+		"CREATE TEMPORARY TABLE `sqlmapper_keys` (`sqlmapper_keys_key` VARCHAR(255))",
+		"INSERT INTO `sqlmapper_keys` (`sqlmapper_keys_key`) VALUES (?), (?)",
+		"INSERT INTO `sqlmapper_keys` (`sqlmapper_keys_key`) VALUES (?), (?)",
+		"INSERT INTO `sqlmapper_keys` (`sqlmapper_keys_key`) VALUES (?)",
+		"SELECT  `field_key`, `field_one`, `field_two`, `field_thr`, `field_fou`  FROM `test_table`  " +
+			"JOIN `sqlmapper_keys` ON `sqlmapper_keys_key` = `field_key` ",
+		"DROP TEMPORARY TABLE `sqlmapper_keys`",
+		"COMMIT",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSQLSelectByPriKeysTempTableDedupe(t *testing.T) {
+
+	var loaded []interface{}
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "INSERT") {
+			for _, a := range args {
+				loaded = append(loaded, a)
+			}
+		}
+		if strings.HasPrefix(q, "SELECT") {
+			return demoRowsResult(3)
+		}
+		return nil
+	})
+	defer db.Close()
+
+	var row DemoRow
+	pg, _ := NewFieldsMapWithDialect(table, &row, Postgres)
+	pg.SetKeysInThreshold(2)
+
+	keys := []interface{}{"keya", "keyb", "keya", "keyc", "keyb"}
+	if _, err := pg.SQLSelectByPriKeys(context.Background(), nil, db, keys); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(loaded) != "[keya keyb keyc]" {
+		t.Errorf("temp table loaded %v, want each key once", loaded)
+	}
+
+	queries := fdb.Queries()
+	if q := queries[len(queries)-2]; q.sql != `DROP TABLE "sqlmapper_keys"` {
+		t.Errorf("got %q, want DROP TABLE on Postgres", q.sql)
+	}
+
+	if u := uniqueKeys([]interface{}{1, int64(1), []byte("a"), "b", []byte("a")}); fmt.Sprint(u) != "[1 [97] b]" {
+		t.Errorf("uniqueKeys got %v", u)
+	}
+}
+
+func TestSQLSelectByPriKeysAbsent(t *testing.T) {
+
+	stored := demoRowsResult(3)
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		res := &fakeResult{cols: stored.cols}
+		for _, r := range stored.rows {
+			for _, a := range args {
+				if r[0] == a {
+					res.rows = append(res.rows, r)
+				}
+			}
+		}
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	if objs, err := fm.SQLSelectByPriKeys(ctx, nil, db, []interface{}{}); err != nil || len(objs) != 0 {
+		t.Errorf("empty keys got %v %v", objs, err)
+	}
+	if n := len(fdb.Queries()); n != 0 {
+		t.Errorf("empty keys should not query, got %d queries", n)
+	}
+
+	objs, err := fm.SQLSelectByPriKeys(ctx, nil, db,
+		[]interface{}{"keya", "keyx", "keyc", "keyz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 {
+		t.Fatalf("got %d rows, want 2", len(objs))
+	}
+	if objs[0].(*DemoRow).FieldKey != "keya" || objs[1].(*DemoRow).FieldKey != "keyc" {
+		t.Errorf("unexpected rows %+v %+v", objs[0], objs[1])
+	}
+	if q := fdb.LastQuery(); len(q.args) != 4 {
+		t.Errorf("all keys should be bound, got %v", q.args)
+	}
+}
+
+func TestSQLSelectMapByPriKeys(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		res := demoRowsResult(3)
+		res.rows = res.rows[1:] // keya is absent
+		return res
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	m, err := fm.SQLSelectMapByPriKeys(context.Background(), nil, db,
+		[]interface{}{"keya", "keyb", "keyc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 {
+		t.Fatalf("got %d rows, want 2", len(m))
+	}
+	if _, ok := m["keya"]; ok {
+		t.Error("absent key should not be in map")
+	}
+	for _, k := range []string{"keyb", "keyc"} {
+		obj, ok := m[k].(*DemoRow)
+		if !ok || obj.FieldKey != k {
+			t.Errorf("key %s got %+v", k, m[k])
+		}
+	}
+	if m["keyb"].(*DemoRow).FieldThr != 1 || m["keyc"].(*DemoRow).FieldThr != 2 {
+		t.Errorf("rows mapped to wrong keys: %+v %+v", m["keyb"], m["keyc"])
+	}
+}
+
+func TestSQLDeleteByPriKeys(t *testing.T) {
+
+	stored := map[string]bool{"keya": true, "keyb": true, "keyc": true, "keyd": true, "keye": true}
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		var n int64
+		for _, a := range args {
+			if stored[a.(string)] {
+				delete(stored, a.(string))
+				n++
+			}
+		}
+		return &fakeResult{affected: n}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	n, err := fm.SQLDeleteByPriKeys(ctx, nil, db, []interface{}{"keya", "keyc", "keye"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || len(stored) != 2 {
+		t.Errorf("got %d deleted, %d left, want 3 & 2", n, len(stored))
+	}
+	q := fdb.LastQuery()
+	if q.sql != "DELETE FROM `test_table`  where `field_key` IN (?, ?, ?) " || len(q.args) != 3 {
+		t.Errorf("unexpected %q %v", q.sql, q.args)
+	}
+
+	if n, err := fm.SQLDeleteByPriKeys(ctx, nil, db, []interface{}{"keya", "keyb"}); err != nil || n != 1 {
+		t.Errorf("want 1 deleted for one absent key, got %d %v", n, err)
+	}
+
+	queries := len(fdb.Queries())
+	if n, err := fm.SQLDeleteByPriKeys(ctx, nil, db, nil); err != nil || n != 0 {
+		t.Errorf("empty keys got %d %v", n, err)
+	}
+	if len(fdb.Queries()) != queries {
+		t.Error("empty keys should not query")
+	}
+}
+
+func TestSQLUpdateFieldsByPriKeys(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{affected: 3}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldOne: "processed", FieldThr: 7}
+	fm, _ := NewFieldsMap(table, &row)
+
+	n, err := fm.SQLUpdateFieldsByPriKeys(ctx, nil, db, []string{"field_one", "field_thr"},
+		[]interface{}{"keya", "keyb", "keyc"})
+	if err != nil || n != 3 {
+		t.Fatalf("got %d %v", n, err)
+	}
+	q := fdb.LastQuery()
+	want := "UPDATE `test_table` SET `field_one` = ?, `field_thr` = ? where `field_key` IN (?, ?, ?) "
+	if q.sql != want {
+		t.Errorf("got %q, want %q", q.sql, want)
+	}
+	if len(q.args) != 5 || q.args[0] != "processed" || q.args[1] != int64(7) ||
+		q.args[2] != "keya" || q.args[4] != "keyc" {
+		t.Errorf("unexpected args %v", q.args)
+	}
+
+	queries := len(fdb.Queries())
+	if n, err := fm.SQLUpdateFieldsByPriKeys(ctx, nil, db, []string{"field_one"}, nil); err != nil || n != 0 {
+		t.Errorf("empty keys got %d %v", n, err)
+	}
+	if len(fdb.Queries()) != queries {
+		t.Error("empty keys should not query")
+	}
+
+	for _, cols := range [][]string{nil, {"no_field"}, {"field_key"}} {
+		if _, err := fm.SQLUpdateFieldsByPriKeys(ctx, nil, db, cols, []interface{}{"keya"}); err == nil {
+			t.Errorf("want error for cols %v", cols)
+		}
+	}
+}
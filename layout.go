@@ -0,0 +1,278 @@
+package sqlmapper
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fieldLayout parsed struct field
+type fieldLayout struct {
+	index   []int // index path of struct field, through embedded structs
+	name    string
+	tag     string
+	typ     string
+	enum    *enumTable
+	ptr     bool   // pointer field, nil is NULL
+	epoch   string // "s" or "ms" for time.Time stored as Unix epoch
+	ordinal int    // column index of `sql:"#n"` tag, -1 if mapped by name
+	hash    bool   // row hash of other fields, by `hash` option
+	comment string // column comment in DDL, by `comment` option
+	coltype string // column type in DDL, by `type` option
+	expr    string // select-only expression, by `expr` option
+	auto    bool   // db assigned primary key, by `auto` option, not inserted
+	stamp   string // "create" or "update" time, by createtime / updatetime option
+	version bool   // optimistic lock version, by `version` option
+	soft    bool   // soft delete marker, by `softdelete` option
+}
+
+// structLayout parsed struct, shared by all objects of the same type
+// and tag key
+type structLayout struct {
+	reftype reflect.Type
+	tagKeys []string
+	fields  []fieldLayout
+	pks     []int         // indexes of primary key fields, by `pk` option or [0]
+	vault   []fieldLayout // fields by `vault` option, not in fields
+}
+
+// defaultTagKey struct tag key of columns, see NewFieldsMapWithTagKey
+const defaultTagKey = "sql"
+
+// layoutKey key of layoutCache, tagKeys joined by commas
+type layoutKey struct {
+	reftype reflect.Type
+	tagKeys string
+}
+
+// layoutCache layoutKey => *structLayout
+var layoutCache sync.Map
+
+// parseStructLayout walk struct fields, columns by the first of tagKeys
+// tags a field has
+func parseStructLayout(reftype reflect.Type, tagKeys []string) (*structLayout, error) {
+
+	if reftype == nil {
+		return nil, errors.New("Unsupported Type: nil")
+	}
+	if reftype.Kind() != reflect.Struct {
+		return nil, errors.New("Unsupported Type: " + reftype.String())
+	}
+
+	var fields, vault []fieldLayout
+	hashed, versioned, softDeleted := false, false, false
+	var pks []int
+	sfs := structFields(reftype, tagKeys)
+	for i, flen := 0, len(sfs); i < flen; i++ {
+
+		sf := sfs[i]
+		if sf.PkgPath != "" || lookupTag(sf.Tag, tagKeys) == "-" {
+			// unexported or transient field, no column
+			continue
+		}
+
+		var field fieldLayout
+		ft := sf.Type
+		field.typ = ft.String()
+		if ft.Kind() == reflect.Ptr {
+			// *int64, *string ... nullable column
+			field.ptr = true
+			ft = ft.Elem()
+		}
+		base := ft.String()
+		if et := lookupEnum(ft); et != nil && !field.ptr {
+			field.typ = "enum"
+			field.enum = et
+		} else if isScanValuer(ft) && !field.ptr {
+			// custom type, bound by driver.Valuer & scanned by sql.Scanner
+			field.typ = "scanner"
+		} else if baseType(base) != "int64" && baseType(base) != "uint64" &&
+			baseType(base) != "float64" && base != "string" && base != "bool" &&
+			base != "time.Time" {
+			return nil, errors.New("Unsupported Type: " + field.typ)
+		}
+
+		var opts tagOptions
+		field.index = sf.Index
+		field.name = sf.Name
+		field.tag, opts = parseTag(lookupTag(sf.Tag, tagKeys))
+		if len(field.tag) == 0 {
+			field.tag = snakeCase(field.name)
+		}
+
+		field.ordinal = -1
+		if strings.HasPrefix(field.tag, "#") {
+			n, err := strconv.Atoi(field.tag[1:])
+			if err != nil || n < 0 {
+				return nil, errors.New("bad column index in `sql` tag: " + field.name)
+			}
+			field.ordinal = n
+		}
+
+		if opts.Has("epoch") {
+			field.epoch = opts["epoch"]
+			if base != "time.Time" {
+				return nil, errors.New("epoch option on non time.Time field: " + field.name)
+			}
+			if field.epoch != "s" && field.epoch != "ms" {
+				return nil, errors.New("epoch must be s or ms: " + field.name)
+			}
+		}
+		if opts.Has("createtime") || opts.Has("updatetime") {
+			if base != "time.Time" {
+				return nil, errors.New("createtime / updatetime option on non time.Time field: " + field.name)
+			}
+			field.stamp = "create"
+			if opts.Has("updatetime") {
+				field.stamp = "update"
+			}
+		}
+		field.comment = opts["comment"]
+		field.coltype = opts["type"]
+		if opts.Has("type") && len(field.coltype) == 0 {
+			return nil, errors.New("type option empty: " + field.name)
+		}
+		field.expr = opts["expr"]
+		if opts.Has("expr") && len(field.expr) == 0 {
+			return nil, errors.New("expr option empty: " + field.name)
+		}
+		if opts.Has("pk") {
+			if len(field.expr) > 0 {
+				return nil, errors.New("expr option on primary key: " + field.name)
+			}
+			pks = append(pks, len(fields))
+		}
+		if opts.Has("auto") {
+			if !opts.Has("pk") {
+				return nil, errors.New("auto option on non primary key: " + field.name)
+			}
+			if field.ptr || (baseType(base) != "int64" && baseType(base) != "uint64") {
+				return nil, errors.New("auto option on non integer field: " + field.name)
+			}
+			field.auto = true
+		}
+		if opts.Has("hash") {
+			if field.typ != "int64" {
+				return nil, errors.New("hash option on non int64 field: " + field.name)
+			}
+			if hashed {
+				return nil, errors.New("more than one hash field: " + field.name)
+			}
+			field.hash = true
+			hashed = true
+		}
+		if opts.Has("version") {
+			if field.ptr || baseType(base) != "int64" || opts.Has("pk") {
+				return nil, errors.New("version option on non integer field or primary key: " + field.name)
+			}
+			if versioned {
+				return nil, errors.New("more than one version field: " + field.name)
+			}
+			field.version = true
+			versioned = true
+		}
+		if opts.Has("softdelete") {
+			if field.typ != "*time.Time" {
+				return nil, errors.New("softdelete option on non *time.Time field: " + field.name)
+			}
+			if softDeleted {
+				return nil, errors.New("more than one softdelete field: " + field.name)
+			}
+			field.soft = true
+			softDeleted = true
+		}
+		if opts.Has("vault") {
+			if opts.Has("pk") || opts.Has("hash") || len(field.expr) > 0 || field.soft {
+				return nil, errors.New("vault option with pk, hash, expr or softdelete: " + field.name)
+			}
+			vault = append(vault, field)
+			continue
+		}
+		fields = append(fields, field)
+	}
+
+	if len(pks) == 0 {
+		pks = []int{0}
+		if len(fields) > 0 && len(fields[0].expr) > 0 {
+			return nil, errors.New("expr option on primary key: " + fields[0].name)
+		}
+	}
+
+	return &structLayout{
+		reftype: reftype,
+		tagKeys: tagKeys,
+		fields:  fields,
+		pks:     pks,
+		vault:   vault,
+	}, nil
+}
+
+// structFields fields of reftype, fields of untagged embedded structs
+// are flattened in place, Index of each is the path from reftype
+func structFields(reftype reflect.Type, tagKeys []string) []reflect.StructField {
+
+	var sfs []reflect.StructField
+	for i, flen := 0, reftype.NumField(); i < flen; i++ {
+		sf := reftype.Field(i)
+		if !isEmbeddedStruct(sf, tagKeys) {
+			sfs = append(sfs, sf)
+			continue
+		}
+
+		for _, sub := range structFields(sf.Type, tagKeys) {
+			sub.Index = append([]int{i}, sub.Index...)
+			sfs = append(sfs, sub)
+		}
+	}
+
+	return sfs
+}
+
+// isEmbeddedStruct sf is an anonymous struct (not pointer) without tag
+// and not a column type itself, e.g. time.Time or a sql.Scanner
+func isEmbeddedStruct(sf reflect.StructField, tagKeys []string) bool {
+
+	return sf.Anonymous && sf.Type.Kind() == reflect.Struct &&
+		len(lookupTag(sf.Tag, tagKeys)) == 0 &&
+		sf.Type != reflect.TypeOf(time.Time{}) && !isScanValuer(sf.Type) &&
+		lookupEnum(sf.Type) == nil
+}
+
+// isScanValuer t implements driver.Valuer and *t sql.Scanner
+func isScanValuer(t reflect.Type) bool {
+
+	return t.Implements(valuerType) && reflect.PointerTo(t).Implements(scannerType)
+}
+
+var (
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// cachedStructLayout parse struct once per type, by `sql` tags
+func cachedStructLayout(reftype reflect.Type) (*structLayout, error) {
+
+	return cachedTagLayout(reftype, []string{defaultTagKey})
+}
+
+// cachedTagLayout parse struct once per type & tag keys
+func cachedTagLayout(reftype reflect.Type, tagKeys []string) (*structLayout, error) {
+
+	key := layoutKey{reftype: reftype, tagKeys: strings.Join(tagKeys, ",")}
+	if v, ok := layoutCache.Load(key); ok {
+		return v.(*structLayout), nil
+	}
+
+	layout, err := parseStructLayout(reftype, tagKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	v, _ := layoutCache.LoadOrStore(key, layout)
+	return v.(*structLayout), nil
+}
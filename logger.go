@@ -0,0 +1,40 @@
+package sqlmapper
+
+import (
+	"context"
+	"time"
+)
+
+// Logger receives every statement executed by a FieldsMap, see SetLogger
+type Logger interface {
+	// LogQuery sqlstr as sent to the driver (placeholders rendered),
+	// its args, time to exec or to query & read rows, and the error if any
+	LogQuery(ctx context.Context, sqlstr string, args []interface{},
+		dur time.Duration, err error)
+}
+
+// SetLogger log statements executed by fds to l, nil to stop logging,
+// no logging by default
+// example: fds.SetLogger(myLogger)
+func (fds *_FieldsMap) SetLogger(l Logger) {
+
+	fds.logger = l
+}
+
+// noLog done func of logStart without logger
+var noLog = func(err error) {}
+
+// logStart start timing sqlstr, call the returned func with its error when done
+func (fds *_FieldsMap) logStart(ctx context.Context, sqlstr string,
+	args []interface{}) func(err error) {
+
+	l := fds.logger
+	if l == nil {
+		return noLog
+	}
+
+	start := time.Now()
+	return func(err error) {
+		l.LogQuery(ctx, fds.rebind(sqlstr), args, time.Since(start), err)
+	}
+}
@@ -0,0 +1,82 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type logEntry struct {
+	sql  string
+	args []interface{}
+	dur  time.Duration
+	err  error
+}
+
+type testLogger struct {
+	entries []logEntry
+}
+
+func (l *testLogger) LogQuery(ctx context.Context, sqlstr string, args []interface{},
+	dur time.Duration, err error) {
+
+	l.entries = append(l.entries, logEntry{sqlstr, args, dur, err})
+}
+
+func TestSetLogger(t *testing.T) {
+
+	errFail := errors.New("fail")
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		time.Sleep(time.Millisecond)
+		switch {
+		case strings.HasPrefix(q, "SELECT"):
+			return demoRowsResult(2)
+		case strings.HasPrefix(q, "DELETE"):
+			return &fakeResult{err: errFail}
+		}
+		return nil
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "keya"}
+	fm, _ := NewFieldsMapWithDialect(table, &row, Postgres)
+
+	// no logger, nothing logged
+	fm.SQLInsert(ctx, nil, db)
+
+	l := &testLogger{}
+	fm.SetLogger(l)
+
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fm.SQLSelectAllRows(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	fm.SQLDeleteByPriKey(ctx, nil, db)
+
+	if len(l.entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(l.entries))
+	}
+	ins := l.entries[0]
+	if ins.sql != `INSERT INTO "test_table" ( "field_key", "field_one", "field_two", "field_thr", "field_fou" ) VALUES ($1, $2, $3, $4, $5)` ||
+		len(ins.args) != 5 || ins.args[0] != "keya" || ins.dur <= 0 || ins.err != nil {
+		t.Errorf("unexpected insert entry %+v", ins)
+	}
+	if sel := l.entries[1]; !strings.HasPrefix(sel.sql, "SELECT") || sel.args != nil || sel.dur <= 0 {
+		t.Errorf("unexpected select entry %+v", sel)
+	}
+	if del := l.entries[2]; !strings.HasPrefix(del.sql, "DELETE") || !errors.Is(del.err, errFail) {
+		t.Errorf("unexpected delete entry %+v", del)
+	}
+
+	fm.SetLogger(nil)
+	fm.SQLInsert(ctx, nil, db)
+	if len(l.entries) != 3 {
+		t.Error("nil logger should stop logging")
+	}
+}
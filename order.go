@@ -0,0 +1,62 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// OrderClause order rows by field NameInDB (`sql` tag), descending if Desc
+type OrderClause struct {
+	NameInDB string
+	Desc     bool
+}
+
+// OrderBy ORDER BY fragment of extStr from clauses,
+// every NameInDB is checked against `sql` tags, so it is safe to build
+// from user input, empty for no clause
+// example: fds.OrderBy([]OrderClause{{"field_thr", true}, {"field_key", false}})
+// => " ORDER BY `field_thr` DESC, `field_key` ASC "
+func (fds *_FieldsMap) OrderBy(clauses []OrderClause) (string, error) {
+
+	if len(clauses) == 0 {
+		return "", nil
+	}
+
+	extStr := " ORDER BY "
+	for i, clen := 0, len(clauses); i < clen; i++ {
+		idx := fds.fieldIndex(clauses[i].NameInDB)
+		if idx < 0 {
+			return "", errors.New("no field match `sql` tag:" + clauses[i].NameInDB)
+		}
+		if i > 0 {
+			extStr += ", "
+		}
+		extStr += fds.quote(fds.fields[idx].Tag)
+		if clauses[i].Desc {
+			extStr += " DESC"
+		} else {
+			extStr += " ASC"
+		}
+	}
+
+	return extStr + " ", nil
+}
+
+// SQLSelectAllRowsOrdered SQLSelectAllRows ordered by clauses
+func (fds *_FieldsMap) SQLSelectAllRowsOrdered(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	clauses []OrderClause) ([]interface{}, error) {
+
+	orderStr, err := fds.OrderBy(clauses)
+	if err != nil {
+		return nil, err
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	extStr, args := fds.scoped(orderStr)
+	return fds.selectRows(ctx, exec, scanByPosition, fds.selectSQL(extStr), args...)
+}
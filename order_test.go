@@ -0,0 +1,43 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestSQLSelectAllRowsOrdered(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return demoRowsResult(2)
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+	fm.SetScope("field_one", "one")
+
+	objs, err := fm.SQLSelectAllRowsOrdered(ctx, nil, db, []OrderClause{
+		{NameInDB: "field_thr", Desc: true},
+		{NameInDB: "field_key"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 {
+		t.Errorf("unexpected rows %v", objs)
+	}
+	q := fdb.LastQuery()
+	want := "SELECT  `field_key`, `field_one`, `field_two`, `field_thr`, `field_fou`  FROM `test_table`   where `test_table`.`field_one` = ? ORDER BY `field_thr` DESC, `field_key` ASC "
+	if q.sql != want {
+		t.Errorf("got %q, want %q", q.sql, want)
+	}
+
+	if s, _ := fm.OrderBy(nil); s != "" {
+		t.Errorf("want empty fragment, got %q", s)
+	}
+	if _, err := fm.OrderBy([]OrderClause{{NameInDB: "field_thr; DROP TABLE x"}}); err == nil {
+		t.Error("want error for unknown column")
+	}
+}
@@ -0,0 +1,27 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// SQLSelectPage select a window of limit rows after skipping offset rows,
+// rows are in db order unless the table is ordered by an index
+// example: fds.SQLSelectPage(ctx, tx, db, 20, 40) // page 3 of 20 rows
+// SELECT ... FROM `test_table` LIMIT ? OFFSET ?
+func (fds *_FieldsMap) SQLSelectPage(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	limit, offset int) ([]interface{}, error) {
+
+	if limit <= 0 || offset < 0 {
+		return nil, errors.New("bad page, limit must be positive and offset not negative")
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	extStr, args := fds.scoped(" LIMIT ? OFFSET ? ", limit, offset)
+	return fds.selectRows(ctx, exec, scanByPosition, fds.selectSQL(extStr), args...)
+}
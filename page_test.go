@@ -0,0 +1,49 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestSQLSelectPage(t *testing.T) {
+
+	all := demoRowsResult(10)
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		limit, offset := int(args[0].(int64)), int(args[1].(int64))
+		res := &fakeResult{cols: all.cols}
+		for i := offset; i < offset+limit && i < len(all.rows); i++ {
+			res.rows = append(res.rows, all.rows[i])
+		}
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	objs, err := fm.SQLSelectPage(ctx, nil, db, 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 3 || objs[0].(*DemoRow).FieldKey != "keya" {
+		t.Errorf("unexpected first page %v", objs)
+	}
+	q := fdb.LastQuery()
+	if q.sql != "SELECT  `field_key`, `field_one`, `field_two`, `field_thr`, `field_fou`  FROM `test_table`  LIMIT ? OFFSET ? " {
+		t.Errorf("unexpected sql %q", q.sql)
+	}
+
+	objs, err = fm.SQLSelectPage(ctx, nil, db, 4, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 || objs[0].(*DemoRow).FieldKey != "keyi" || objs[1].(*DemoRow).FieldKey != "keyj" {
+		t.Errorf("unexpected last page %v", objs)
+	}
+
+	if _, err := fm.SQLSelectPage(ctx, nil, db, 0, 0); err == nil {
+		t.Error("want error for zero limit")
+	}
+}
@@ -0,0 +1,81 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+)
+
+// PluckNull how SQLPluck handles NULL of a non-pointer field
+type PluckNull struct {
+	// Skip NULL is not in result
+	Skip bool
+	// Default value for NULL, zero value of the field if nil
+	Default interface{}
+}
+
+// SetPluckNull set how SQLPluck handles NULL
+func (fds *_FieldsMap) SetPluckNull(opt PluckNull) {
+
+	fds.pluckNull = opt
+}
+
+// SQLPluck select values of one field by `sql` tag,
+// extStr & args for WHERE etc., an empty result is an empty slice
+// example: fds.SQLPluck(ctx, tx, db, "field_one", " where `field_thr` > ? ", 10)
+func (fds *_FieldsMap) SQLPluck(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	nameInDB string, extStr string, args ...interface{}) ([]interface{}, error) {
+
+	idx := fds.fieldIndex(nameInDB)
+	if idx < 0 {
+		return nil, errors.New("no field match `sql` tag:" + nameInDB)
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []interface{}{}
+	extStr, args = fds.scoped(extStr, args...)
+	sqlstr := fds.verb("SELECT") + fds.selectColumn(idx) + " FROM " + fds.quoteTable() + " " + extStr
+	err = fds.queryRows(ctx, exec, sqlstr, args, func(rs *sql.Rows) error {
+		for rs.Next() {
+			obj := reflect.New(fds.reftype).Interface()
+			fieldsMap, err := fds.newRowMap(obj)
+			if err != nil {
+				return err
+			}
+
+			err = scanRow(rs, fieldsMap.GetFieldSaveAddr(idx))
+			if err != nil {
+				return err
+			}
+
+			if !fieldsMap.saveValid(idx) {
+				if fds.pluckNull.Skip {
+					continue
+				}
+				if fds.pluckNull.Default != nil {
+					values = append(values, fds.pluckNull.Default)
+					continue
+				}
+			}
+
+			fieldsMap.nullPolicy = LeaveOnNull
+			err = fieldsMap.mapBackField(idx)
+			if err != nil {
+				return err
+			}
+			values = append(values, fieldsMap.fieldGoValue(idx))
+		}
+
+		return rs.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,57 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestSQLPluck(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if len(args) > 0 {
+			return &fakeResult{cols: []string{"field_thr"}}
+		}
+		return &fakeResult{
+			cols: []string{"field_thr"},
+			rows: [][]driver.Value{{int64(1)}, {nil}, {int64(3)}},
+		}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	values, err := fm.SQLPluck(ctx, nil, db, "field_thr", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 3 || values[0] != int64(1) || values[1] != int64(0) || values[2] != int64(3) {
+		t.Errorf("default NULL as zero, got %v", values)
+	}
+	if q := fdb.LastQuery(); q.sql != "SELECT `field_thr` FROM `test_table` " {
+		t.Errorf("unexpected sql %q", q.sql)
+	}
+
+	fm.SetPluckNull(PluckNull{Default: int64(-1)})
+	values, _ = fm.SQLPluck(ctx, nil, db, "field_thr", "")
+	if len(values) != 3 || values[1] != int64(-1) {
+		t.Errorf("NULL as default, got %v", values)
+	}
+
+	fm.SetPluckNull(PluckNull{Skip: true})
+	values, _ = fm.SQLPluck(ctx, nil, db, "field_thr", "")
+	if len(values) != 2 || values[1] != int64(3) {
+		t.Errorf("NULL skipped, got %v", values)
+	}
+
+	values, err = fm.SQLPluck(ctx, nil, db, "field_thr", " where `field_thr` > ? ", 100)
+	if err != nil || values == nil || len(values) != 0 {
+		t.Errorf("want empty non-nil slice, got %#v %v", values, err)
+	}
+
+	if _, err := fm.SQLPluck(ctx, nil, db, "nope", ""); err == nil {
+		t.Error("want error for unknown field")
+	}
+}
@@ -0,0 +1,49 @@
+package sqlmapper
+
+// Priority MySQL statement priority modifier
+type Priority int
+
+const (
+	// DefaultPriority no modifier (default)
+	DefaultPriority Priority = iota
+	// HighPriority HIGH_PRIORITY on SELECT & INSERT
+	HighPriority
+	// LowPriority LOW_PRIORITY on INSERT, UPDATE & DELETE
+	LowPriority
+)
+
+// SetPriority set priority modifier of generated statements,
+// a modifier not allowed on a statement is left out,
+// e.g. LowPriority on SELECT or HighPriority on UPDATE,
+// and so are all modifiers with a dialect other than MySQL
+// example: fds.SetPriority(sqlmapper.LowPriority)
+// UPDATE LOW_PRIORITY `test_table` SET ...
+func (fds *_FieldsMap) SetPriority(priority Priority) {
+
+	fds.priority = priority
+}
+
+// verb statement keyword with priority modifier
+// example: fds.verb("SELECT") returns "SELECT HIGH_PRIORITY "
+func (fds *_FieldsMap) verb(keyword string) string {
+
+	if _, my := fds.dialect.(mysqlDialect); !my {
+		return keyword + " "
+	}
+
+	var modifier string
+	switch fds.priority {
+	case HighPriority:
+		if keyword == "SELECT" || keyword == "INSERT" {
+			modifier = "HIGH_PRIORITY "
+		}
+		break
+	case LowPriority:
+		if keyword == "INSERT" || keyword == "UPDATE" || keyword == "DELETE" {
+			modifier = "LOW_PRIORITY "
+		}
+		break
+	}
+
+	return keyword + " " + modifier
+}
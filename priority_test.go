@@ -0,0 +1,77 @@
+package sqlmapper
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSetPriority(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "key001"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	fm.SetPriority(LowPriority)
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, "UPDATE LOW_PRIORITY `test_table` SET ") {
+		t.Errorf("got %q", q.sql)
+	}
+	if err := fm.SQLDeleteByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, "DELETE LOW_PRIORITY FROM `test_table` ") {
+		t.Errorf("got %q", q.sql)
+	}
+	fm.SQLSelectAllRows(ctx, nil, db)
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, "SELECT  `field_key`") {
+		t.Errorf("LOW_PRIORITY should be left out of SELECT, got %q", q.sql)
+	}
+
+	fm.SetPriority(HighPriority)
+	fm.SQLSelectAllRows(ctx, nil, db)
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, "SELECT HIGH_PRIORITY  `field_key`") {
+		t.Errorf("got %q", q.sql)
+	}
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, "INSERT HIGH_PRIORITY INTO `test_table` ") {
+		t.Errorf("got %q", q.sql)
+	}
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, "UPDATE `test_table` SET ") {
+		t.Errorf("HIGH_PRIORITY should be left out of UPDATE, got %q", q.sql)
+	}
+}
+
+func TestSetPriorityPostgres(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "key001"}
+	fm, _ := NewFieldsMapWithDialect(table, &row, Postgres)
+
+	fm.SetPriority(HighPriority)
+	fm.SQLSelectAllRows(ctx, nil, db)
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, `SELECT  "field_key"`) {
+		t.Errorf("HIGH_PRIORITY should be left out on Postgres, got %q", q.sql)
+	}
+
+	fm.SetPriority(LowPriority)
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasPrefix(q.sql, `UPDATE "test_table" SET `) {
+		t.Errorf("LOW_PRIORITY should be left out on Postgres, got %q", q.sql)
+	}
+}
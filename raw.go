@@ -0,0 +1,302 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+)
+
+// scanMode how columns of a raw query map to Fields
+type scanMode int
+
+const (
+	// scanByPosition column i => field i
+	scanByPosition scanMode = iota
+	// scanByName column => field by `sql` tag, unmapped columns ignored
+	scanByName
+	// scanByNamePartial scanByName, fields without column are left zero
+	scanByNamePartial
+)
+
+// SQLRawSelect run a raw query, columns map to fields by position
+func (fds *_FieldsMap) SQLRawSelect(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, sqlstr string, args ...interface{}) ([]interface{}, error) {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return fds.selectRows(ctx, exec, scanByPosition, sqlstr, args...)
+}
+
+// SQLRawSelectByName run a raw query, columns map to fields by `sql` tag,
+// so column order may differ from field order and
+// columns not in fields are ignored
+func (fds *_FieldsMap) SQLRawSelectByName(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, sqlstr string, args ...interface{}) ([]interface{}, error) {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return fds.selectRows(ctx, exec, scanByName, sqlstr, args...)
+}
+
+// SQLRawSelectPartial run a raw query, columns map to fields by `sql` tag,
+// fields without column in result are left zero,
+// return rows and tags of fields loaded
+// example: objs, loaded, err := fds.SQLRawSelectPartial(ctx, tx, db,
+// 	"SELECT field_key, field_thr FROM test_table")
+// loaded is ["field_key", "field_thr"]
+func (fds *_FieldsMap) SQLRawSelectPartial(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, sqlstr string, args ...interface{}) ([]interface{}, []string, error) {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fds.selectRowsLoaded(ctx, exec, scanByNamePartial, sqlstr, args...)
+}
+
+// selectRows run sqlstr, scan rows by mode
+func (fds *_FieldsMap) selectRows(ctx context.Context, exec Executor,
+	mode scanMode, sqlstr string, args ...interface{}) ([]interface{}, error) {
+
+	objs, _, err := fds.selectRowsLoaded(ctx, exec, mode, sqlstr, args...)
+	return objs, err
+}
+
+// selectRowsLoaded run sqlstr, scan rows by mode,
+// return rows and tags of fields loaded
+func (fds *_FieldsMap) selectRowsLoaded(ctx context.Context, exec Executor,
+	mode scanMode, sqlstr string, args ...interface{}) ([]interface{}, []string, error) {
+
+	key := fds.cacheKey(mode, sqlstr, args)
+	if len(key) > 0 {
+		if objs, loaded, ok := fds.cacheGet(key); ok {
+			return objs, loaded, nil
+		}
+	}
+
+	objs := []interface{}{}
+	loaded, err := fds.scanEach(ctx, exec, mode, sqlstr, args, func(fieldsMap *_FieldsMap) error {
+		objs = append(objs, fieldsMap.objptr)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(key) > 0 {
+		fds.cachePut(key, objs, loaded)
+	}
+	return objs, loaded, nil
+}
+
+// scanEach run sqlstr, scan each row by mode into a new object,
+// call fn with FieldsMap of the object, an error from fn stops scan.
+// return tags of fields loaded
+func (fds *_FieldsMap) scanEach(ctx context.Context, exec Executor,
+	mode scanMode, sqlstr string, args []interface{},
+	fn func(fieldsMap *_FieldsMap) error) ([]string, error) {
+
+	var loaded []string
+	err := fds.queryRows(ctx, exec, sqlstr, args, func(rs *sql.Rows) error {
+
+		var err error
+		loaded, err = fds.scanRows(rs, mode, fn)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loaded, nil
+}
+
+// scanRows scan each row of rs by mode into a new object,
+// call fn with FieldsMap of the object, an error from fn stops scan,
+// the FieldsMap is rebound to the object of the next row, fn must not keep it.
+// rs is not closed, return tags of fields loaded
+func (fds *_FieldsMap) scanRows(rs *sql.Rows, mode scanMode,
+	fn func(fieldsMap *_FieldsMap) error) ([]string, error) {
+
+	loaded := fds.GetFieldNamesInDB()
+	var idxs []int
+	if mode != scanByPosition {
+		cols, err := rs.Columns()
+		if err != nil {
+			return nil, err
+		}
+
+		idxs, err = fds.columnIndexes(cols, mode == scanByNamePartial)
+		if err != nil {
+			return nil, err
+		}
+
+		loaded = []string{}
+		for i, ilen := 0, len(idxs); i < ilen; i++ {
+			if idxs[i] >= 0 {
+				loaded = append(loaded, fds.fields[idxs[i]].Tag)
+			}
+		}
+	}
+
+	var discard interface{}
+	var fieldsMap *_FieldsMap
+	for rs.Next() {
+		obj := reflect.New(fds.reftype).Interface()
+		var err error
+		if fieldsMap == nil {
+			fieldsMap, err = fds.newRowMap(obj)
+		} else {
+			err = fieldsMap.Rebind(obj)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		addrs := fieldsMap.GetFieldSaveAddrs()
+		if mode != scanByPosition {
+			addrs = make([]interface{}, len(idxs))
+			for i, ilen := 0, len(idxs); i < ilen; i++ {
+				if idxs[i] < 0 {
+					addrs[i] = &discard
+				} else {
+					addrs[i] = fieldsMap.GetFieldSaveAddr(idxs[i])
+				}
+			}
+		}
+
+		err = scanRow(rs, addrs...)
+		if err != nil {
+			return nil, err
+		}
+		if mode == scanByNamePartial {
+			for i, ilen := 0, len(idxs); i < ilen; i++ {
+				if idxs[i] >= 0 {
+					err = fieldsMap.mapBackField(idxs[i])
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+		} else {
+			_, err = fieldsMap.mapBack()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = fn(fieldsMap)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return loaded, rs.Err()
+}
+
+// ScanRows scan rows of a hand-written query into sliceptr (*[]T or *[]*T
+// of a struct T), columns map to fields by `sql` tag like SQLRawSelectByName,
+// rows are appended, rows is not closed (but drained)
+// example: rs, _ := db.QueryContext(ctx, "SELECT a.*, b.x FROM a JOIN b ...")
+// defer rs.Close()
+// var rows []DemoRow
+// err := sqlmapper.ScanRows(rs, "test_table", &rows)
+func ScanRows(rows *sql.Rows, table string, sliceptr interface{}) error {
+
+	sv := reflect.ValueOf(sliceptr)
+	if sv.Kind() != reflect.Ptr || sv.IsNil() || sv.Elem().Kind() != reflect.Slice {
+		return errors.New("ScanRows needs pointer to slice, got " + reflect.TypeOf(sliceptr).String())
+	}
+	slice := sv.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if elemType.Kind() == reflect.Ptr {
+		structType = elemType.Elem()
+	}
+
+	fm, err := NewFieldsMap(table, reflect.New(structType).Interface())
+	if err != nil {
+		return err
+	}
+
+	_, err = fm.(*_FieldsMap).scanRows(rows, scanByName, func(fieldsMap *_FieldsMap) error {
+		obj := reflect.ValueOf(fieldsMap.objptr)
+		if elemType.Kind() != reflect.Ptr {
+			obj = obj.Elem()
+		}
+		slice.Set(reflect.Append(slice, obj))
+		return nil
+	})
+
+	return err
+}
+
+// ScanOne scan row of a hand-written query into objptr (pointer to struct),
+// *sql.Row has no column names, so columns map to fields by position,
+// sql.ErrNoRows if no row
+// example: row := db.QueryRowContext(ctx, "SELECT field_key, ... FROM test_table WHERE ...", v)
+// err := sqlmapper.ScanOne(row, "test_table", &demoRow)
+func ScanOne(row *sql.Row, table string, objptr interface{}) error {
+
+	fm, err := NewFieldsMap(table, objptr)
+	if err != nil {
+		return err
+	}
+
+	fds := fm.(*_FieldsMap)
+	err = row.Scan(fds.GetFieldSaveAddrs()...)
+	if err != nil {
+		return err
+	}
+
+	_, err = fds.mapBack()
+	return err
+}
+
+// columnIndexes field index of each column, -1 if no field match,
+// fields with `sql:"#n"` tag match column n, others match by name,
+// error if a field has no column unless partial
+func (fds *_FieldsMap) columnIndexes(cols []string, partial bool) ([]int, error) {
+
+	idxs := make([]int, len(cols))
+	for i, clen := 0, len(cols); i < clen; i++ {
+		idxs[i] = -1
+	}
+
+	found := make([]bool, len(fds.fields))
+	for j, flen := 0, len(fds.fields); j < flen; j++ {
+		ordinal := fds.fields[j].ordinal
+		if ordinal >= 0 && ordinal < len(cols) && idxs[ordinal] < 0 {
+			idxs[ordinal] = j
+			found[j] = true
+		}
+	}
+
+	for i, clen := 0, len(cols); i < clen; i++ {
+		if idxs[i] >= 0 {
+			continue
+		}
+		for j, flen := 0, len(fds.fields); j < flen; j++ {
+			if !found[j] && fds.fields[j].ordinal < 0 && fds.fields[j].Tag == cols[i] {
+				idxs[i] = j
+				found[j] = true
+				break
+			}
+		}
+	}
+
+	for j, flen := 0, len(fds.fields); j < flen; j++ {
+		if !found[j] && !partial {
+			return nil, errors.New("no column match `sql` tag:" + fds.fields[j].Tag)
+		}
+	}
+
+	return idxs, nil
+}
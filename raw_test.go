@@ -0,0 +1,244 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestSQLRawSelectByName(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"field_fou", "extra", "field_key", "field_thr", "field_two", "field_one"},
+			rows: [][]driver.Value{
+				{1.5, "ignored", "key001", int64(7), true, "one"},
+			},
+		}
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, err := NewFieldsMap(table, &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objs, err := fm.SQLRawSelectByName(context.Background(), nil, db,
+		"SELECT t.*, 'ignored' AS extra FROM test_table t WHERE field_thr = ?", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 1 {
+		t.Fatalf("got %d rows, want 1", len(objs))
+	}
+
+	want := DemoRow{FieldKey: "key001", FieldOne: "one", FieldTwo: true, FieldThr: 7, FieldFou: 1.5}
+	if got := *objs[0].(*DemoRow); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestSQLRawSelectByNameMissingColumn(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"field_key", "field_one"},
+			rows: [][]driver.Value{{"key001", "one"}},
+		}
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, err := NewFieldsMap(table, &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = fm.SQLRawSelectByName(context.Background(), nil, db,
+		"SELECT field_key, field_one FROM test_table")
+	if err == nil {
+		t.Error("want error for missing columns")
+	}
+}
+
+func TestSQLRawSelect(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return demoRowsResult(2)
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, err := NewFieldsMap(table, &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objs, err := fm.SQLRawSelect(context.Background(), nil, db, "SELECT * FROM test_table")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 || objs[1].(*DemoRow).FieldThr != 1 {
+		t.Errorf("unexpected rows %v", objs)
+	}
+}
+
+func TestSQLRawSelectPartial(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"field_thr", "field_key", "extra"},
+			rows: [][]driver.Value{{int64(7), "key001", "x"}},
+		}
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+	fm.SetNullPolicy(ErrorOnNull)
+
+	objs, loaded, err := fm.SQLRawSelectPartial(context.Background(), nil, db,
+		"SELECT field_thr, field_key, 'x' AS extra FROM test_table")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 || loaded[0] != "field_thr" || loaded[1] != "field_key" {
+		t.Errorf("loaded %v", loaded)
+	}
+
+	want := DemoRow{FieldKey: "key001", FieldThr: 7}
+	if got := *objs[0].(*DemoRow); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// viewRow columns of a view mapped by index
+type viewRow struct {
+	Key   string `sql:"#0"`
+	Count int64  `sql:"#2"`
+	Name  string `sql:"name"`
+}
+
+func TestSQLRawSelectByOrdinal(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"COALESCE(a)", "name", "COUNT(*)"},
+			rows: [][]driver.Value{{"key001", "one", int64(3)}},
+		}
+	})
+	defer db.Close()
+
+	var row viewRow
+	fm, err := NewFieldsMap("legacy_view", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objs, err := fm.SQLRawSelectByName(context.Background(), nil, db, "SELECT * FROM legacy_view")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := viewRow{Key: "key001", Count: 3, Name: "one"}
+	if got := *objs[0].(*viewRow); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	type badRow struct {
+		Key string `sql:"#x"`
+	}
+	if _, err := NewFieldsMap("legacy_view", &badRow{}); err == nil {
+		t.Error("want error for bad column index")
+	}
+}
+
+func TestScanRows(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		res := demoRowsResult(3)
+		res.cols = append(res.cols, "joined_col")
+		for i := range res.rows {
+			res.rows[i] = append(res.rows[i], "x")
+		}
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	rs, err := db.QueryContext(ctx, "SELECT t.*, j.joined_col FROM test_table t JOIN j ON ...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+
+	var rows []DemoRow
+	if err := ScanRows(rs, table, &rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[1].FieldKey != "keyb" || rows[2].FieldThr != 2 {
+		t.Errorf("unexpected %+v", rows)
+	}
+
+	rs2, _ := db.QueryContext(ctx, "SELECT ...")
+	defer rs2.Close()
+	var ptrs []*DemoRow
+	if err := ScanRows(rs2, table, &ptrs); err != nil {
+		t.Fatal(err)
+	}
+	if len(ptrs) != 3 || ptrs[0].FieldOne != "one" {
+		t.Errorf("unexpected %v", ptrs)
+	}
+
+	if err := ScanRows(rs2, table, rows); err == nil {
+		t.Error("want error for non pointer")
+	}
+}
+
+func TestScanOne(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if args[0] == "keyb" {
+			return demoRowsResult(2)
+		}
+		return &fakeResult{cols: demoRowsResult(0).cols}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	err := ScanOne(db.QueryRowContext(ctx, "SELECT * FROM test_table WHERE field_key = ?", "keyb"), table, &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.FieldKey != "keya" || row.FieldOne != "one" || !row.FieldTwo {
+		t.Errorf("unexpected %+v", row)
+	}
+
+	err = ScanOne(db.QueryRowContext(ctx, "SELECT * FROM test_table WHERE field_key = ?", "nope"), table, &row)
+	if err != sql.ErrNoRows {
+		t.Errorf("want sql.ErrNoRows, got %v", err)
+	}
+}
+
+func BenchmarkSQLSelectAllRows10k(b *testing.B) {
+
+	res := demoRowsResult(10000)
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := fm.SQLSelectAllRows(ctx, nil, db); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
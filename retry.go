@@ -0,0 +1,114 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryCodes transient error codes safe to retry,
+// MySQL 1213 deadlock and SQLSTATE 40001 serialization failure
+var DefaultRetryCodes = []string{"1213", "40001"}
+
+// RetryPolicy retries of RunInTxRetry
+type RetryPolicy struct {
+	MaxRetries int           // retries after the first attempt
+	Backoff    time.Duration // sleep before the first retry, doubled each retry
+	Codes      []string      // error codes to retry, DefaultRetryCodes if nil
+}
+
+// RetryableError err is a MySQL deadlock or a serialization failure,
+// see DefaultRetryCodes
+func RetryableError(err error) bool {
+
+	return errorCodeIn(err, DefaultRetryCodes)
+}
+
+// RunInTxRetry RunInTx, run again in a new tx while fn or commit fails
+// with an error code of policy, up to policy.MaxRetries times,
+// fn must be safe to run more than once
+// example: err := sqlmapper.RunInTxRetry(ctx, db, nil,
+// 	sqlmapper.RetryPolicy{MaxRetries: 3, Backoff: 10 * time.Millisecond},
+// 	func(tx *sql.Tx) error { return fm.SQLUpdateByPriKey(ctx, tx, nil) })
+func RunInTxRetry(ctx context.Context, db *sql.DB, opts *sql.TxOptions,
+	policy RetryPolicy, fn func(tx *sql.Tx) error) error {
+
+	codes := policy.Codes
+	if codes == nil {
+		codes = DefaultRetryCodes
+	}
+
+	backoff := policy.Backoff
+	for retry := 0; ; retry++ {
+		err := RunInTx(ctx, db, opts, fn)
+		if err == nil || retry >= policy.MaxRetries || !errorCodeIn(err, codes) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// errorCodeIn code of err or an error it wraps is one of codes
+func errorCodeIn(err error, codes []string) bool {
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		for _, code := range errorCodes(err) {
+			for i, clen := 0, len(codes); i < clen; i++ {
+				if code == codes[i] {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// errorCodes codes of a driver error without importing drivers:
+// SQLState() of pq / pgx errors, Number & SQLState of MySQL errors,
+// Code of other errors
+func errorCodes(err error) []string {
+
+	var codes []string
+	if s, ok := err.(interface{ SQLState() string }); ok {
+		codes = append(codes, s.SQLState())
+	}
+
+	v := reflect.ValueOf(err)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return codes
+	}
+
+	for _, name := range []string{"Number", "Code", "SQLState"} {
+		f := v.FieldByName(name)
+		switch {
+		case !f.IsValid():
+		case f.Kind() >= reflect.Int && f.Kind() <= reflect.Int64:
+			codes = append(codes, strconv.FormatInt(f.Int(), 10))
+		case f.Kind() >= reflect.Uint && f.Kind() <= reflect.Uint64:
+			codes = append(codes, strconv.FormatUint(f.Uint(), 10))
+		case f.Kind() == reflect.String:
+			codes = append(codes, f.String())
+		case f.Kind() == reflect.Array && f.Type().Elem().Kind() == reflect.Uint8:
+			b := make([]byte, f.Len())
+			reflect.Copy(reflect.ValueOf(b), f)
+			codes = append(codes, string(b))
+		}
+	}
+
+	return codes
+}
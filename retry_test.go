@@ -0,0 +1,107 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeMySQLError like *mysql.MySQLError
+type fakeMySQLError struct {
+	Number   uint16
+	SQLState [5]byte
+	Message  string
+}
+
+func (e *fakeMySQLError) Error() string {
+	return fmt.Sprintf("Error %d: %s", e.Number, e.Message)
+}
+
+// fakePgError like *pgconn.PgError
+type fakePgError struct {
+	Code string
+}
+
+func (e *fakePgError) Error() string    { return "ERROR: " + e.Code }
+func (e *fakePgError) SQLState() string { return e.Code }
+
+func TestRetryableError(t *testing.T) {
+
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&fakeMySQLError{Number: 1213, Message: "Deadlock found"}, true},
+		{&fakeMySQLError{Number: 1062, SQLState: [5]byte{'2', '3', '0', '0', '0'}}, false},
+		{&fakeMySQLError{Number: 1205, SQLState: [5]byte{'4', '0', '0', '0', '1'}}, true},
+		{fmt.Errorf("update: %w", &fakePgError{Code: "40001"}), true},
+		{&fakePgError{Code: "23505"}, false},
+		{errors.New("1213"), false},
+		{nil, false},
+	}
+	for i, c := range cases {
+		if got := RetryableError(c.err); got != c.want {
+			t.Errorf("case %d %v got %v", i, c.err, got)
+		}
+	}
+}
+
+func TestRunInTxRetry(t *testing.T) {
+
+	fails := 2
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "UPDATE") && fails > 0 {
+			fails--
+			return &fakeResult{err: &fakeMySQLError{Number: 1213, Message: "Deadlock found"}}
+		}
+		return &fakeResult{affected: 1}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "keya"}
+	fm, _ := NewFieldsMap(table, &row)
+
+	runs := 0
+	fn := func(tx *sql.Tx) error {
+		runs++
+		if err := fm.SQLInsert(ctx, tx, nil); err != nil {
+			return err
+		}
+		return fm.SQLUpdateByPriKey(ctx, tx, nil)
+	}
+
+	err := RunInTxRetry(ctx, db, nil, RetryPolicy{MaxRetries: 3}, fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs != 3 {
+		t.Errorf("fn should run 3 times, got %d", runs)
+	}
+	var got []string
+	for _, q := range fdb.Queries() {
+		if q.sql == "BEGIN" || q.sql == "COMMIT" || q.sql == "ROLLBACK" {
+			got = append(got, q.sql)
+		}
+	}
+	want := "BEGIN ROLLBACK BEGIN ROLLBACK BEGIN COMMIT"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+
+	fails, runs = 5, 0
+	err = RunInTxRetry(ctx, db, nil, RetryPolicy{MaxRetries: 1}, fn)
+	if !RetryableError(err) || runs != 2 {
+		t.Errorf("want deadlock after 2 runs, got %v %d", err, runs)
+	}
+
+	fails, runs = 5, 0
+	err = RunInTxRetry(ctx, db, nil, RetryPolicy{MaxRetries: 3, Codes: []string{"40001"}}, fn)
+	if err == nil || runs != 1 {
+		t.Errorf("1213 not in codes should not retry, got %v %d", err, runs)
+	}
+}
@@ -0,0 +1,27 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SQLInsertReturning insert and scan all columns of the inserted row back
+// into Object(struct) in one round trip, so db generated values
+// (id, created_at, defaults) are set, for Postgres & MariaDB 10.5+.
+// as SQLInsert, insert hooks are called and timestamps, scope & vault row set
+// INSERT INTO `t` (...) VALUES (...) RETURNING `field0`, `field1`, ...
+func (fds *_FieldsMap) SQLInsertReturning(ctx context.Context, tx *sql.Tx,
+	db *sql.DB) error {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = fds.insert(ctx, exec, true)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
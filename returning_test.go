@@ -0,0 +1,94 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSQLInsertReturning(t *testing.T) {
+
+	created := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "created", "seen_ms", "seen_s"},
+			rows: [][]driver.Value{{int64(42), created, nil, nil}},
+		}
+	})
+	defer db.Close()
+
+	var row timeRow
+	fm, _ := NewFieldsMap("time_table", &row)
+
+	err := fm.SQLInsertReturning(context.Background(), nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "INSERT INTO `time_table` ( `id`, `created`, `seen_ms`, `seen_s` ) VALUES (?, ?, ?, ?)" +
+		" RETURNING `id`, `created`, `seen_ms`, `seen_s` "
+	if q := fdb.LastQuery(); q.sql != want || len(q.args) != 4 {
+		t.Errorf("got %q %v\nwant %q", q.sql, q.args, want)
+	}
+	if row.ID != 42 || !row.Created.Equal(created) {
+		t.Errorf("returned row not scanned back: %+v", row)
+	}
+}
+
+func TestSQLInsertReturningAsInsert(t *testing.T) {
+
+	clock := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.Contains(q, "hooked_table") {
+			return &fakeResult{
+				cols: []string{"id", "name", "status"},
+				rows: [][]driver.Value{{int64(1), "bob", "new"}},
+			}
+		}
+		return &fakeResult{
+			cols: []string{"id", "name", "created_at", "updated_at"},
+			rows: [][]driver.Value{{int64(7), "ann", clock, clock}},
+		}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := stampedRow{Name: "ann"}
+	fm, _ := NewFieldsMap("stamped_table", &row)
+	fm.SetClock(func() time.Time { return clock })
+	fm.SetQueryCache(time.Minute)
+
+	if _, err := fm.SQLSelectAllRows(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	n := len(fdb.Queries())
+
+	if err := fm.SQLInsertReturning(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.args[2] != clock || q.args[3] != clock {
+		t.Errorf("insert should bind timestamps, got %v", q.args)
+	}
+	if row.ID != 7 {
+		t.Errorf("returned row not scanned back: %+v", row)
+	}
+
+	if _, err := fm.SQLSelectAllRows(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if len(fdb.Queries()) != n+2 {
+		t.Error("insert returning should drop the query cache")
+	}
+
+	hooked := hookedRow{ID: 1, Name: "bob"}
+	hfm, _ := NewFieldsMap("hooked_table", &hooked)
+	if err := hfm.SQLInsertReturning(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if len(hooked.calls) != 2 || hooked.calls[0] != "before insert" ||
+		hooked.calls[1] != "after insert" {
+		t.Errorf("insert hooks not called: %v", hooked.calls)
+	}
+}
@@ -0,0 +1,95 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// status stored as its name
+type status int
+
+func (s status) Value() (driver.Value, error) {
+
+	return []string{"draft", "live"}[s], nil
+}
+
+func (s *status) Scan(src interface{}) error {
+
+	switch src {
+	case "draft":
+		*s = 0
+	case "live":
+		*s = 1
+	default:
+		return errors.New("bad status")
+	}
+	return nil
+}
+
+// tags stored as JSON
+type tags []string
+
+func (t tags) Value() (driver.Value, error) {
+
+	b, err := json.Marshal([]string(t))
+	return string(b), err
+}
+
+func (t *tags) Scan(src interface{}) error {
+
+	if src == nil {
+		*t = nil
+		return nil
+	}
+	return json.Unmarshal([]byte(src.(string)), t)
+}
+
+type postRow struct {
+	ID     int64          `sql:"id"`
+	Status status         `sql:"status"`
+	Tags   tags           `sql:"tags"`
+	Note   sql.NullString `sql:"note"`
+}
+
+func TestScannerValuerFields(t *testing.T) {
+
+	var saved []driver.Value
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "INSERT") {
+			saved = args
+			return nil
+		}
+		return &fakeResult{cols: []string{"id", "status", "tags", "note"}, rows: [][]driver.Value{saved}}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := postRow{ID: 1, Status: 1, Tags: tags{"go", "sql"}}
+	fm, err := NewFieldsMap("posts", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.args[1] != "live" || q.args[2] != `["go","sql"]` || q.args[3] != nil {
+		t.Errorf("unexpected binds %#v", q.args)
+	}
+
+	var got postRow
+	got.ID = 1
+	gm, _ := NewFieldsMap("posts", &got)
+	gm.SetNullPolicy(ErrorOnNull)
+	if _, err := gm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != 1 || len(got.Tags) != 2 || got.Tags[1] != "sql" || got.Note.Valid {
+		t.Errorf("unexpected %+v", got)
+	}
+}
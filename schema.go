@@ -0,0 +1,188 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// SchemaIssue column of table not matching its field
+type SchemaIssue struct {
+	Column   string
+	Expected string // column type of the field, e.g. BIGINT
+	Actual   string // column type in db, empty if column is missing
+	Fix      string // suggested statement, never executed
+}
+
+func (i SchemaIssue) String() string {
+
+	if len(i.Actual) == 0 {
+		return fmt.Sprintf("`%s` missing, want %s, fix: %s", i.Column, i.Expected, i.Fix)
+	}
+	return fmt.Sprintf("`%s` is %s, want %s, fix: %s", i.Column, i.Actual, i.Expected, i.Fix)
+}
+
+// SchemaError issues found by SQLValidateSchema
+type SchemaError struct {
+	Table  string
+	Issues []SchemaIssue
+}
+
+func (e *SchemaError) Error() string {
+
+	var issues []string
+	for i, ilen := 0, len(e.Issues); i < ilen; i++ {
+		issues = append(issues, e.Issues[i].String())
+	}
+
+	return "schema of `" + e.Table + "` mismatch: " + strings.Join(issues, "; ")
+}
+
+// SQLValidateSchema check table has a column of compatible type for
+// each field, *SchemaError with expected & actual type and a suggested
+// ALTER for each mismatch. read-only, nothing is altered.
+// type is checked only if the driver reports DatabaseTypeName
+func (fds *_FieldsMap) SQLValidateSchema(ctx context.Context, tx *sql.Tx,
+	db *sql.DB) error {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return err
+	}
+
+	var types []*sql.ColumnType
+	sqlstr := "SELECT * FROM " + fds.quoteTable() + " WHERE 1 = 0"
+	err = fds.queryRows(ctx, exec, sqlstr, nil, func(rs *sql.Rows) error {
+		var err error
+		types, err = rs.ColumnTypes()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var issues []SchemaIssue
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if len(fds.fields[i].expr) > 0 {
+			continue
+		}
+
+		var ct *sql.ColumnType
+		for j, tlen := 0, len(types); j < tlen; j++ {
+			if strings.EqualFold(types[j].Name(), fds.fields[i].Tag) {
+				ct = types[j]
+				break
+			}
+		}
+
+		if ct == nil {
+			issues = append(issues, SchemaIssue{
+				Column:   fds.fields[i].Tag,
+				Expected: fds.columnType(i),
+				Fix:      "ALTER TABLE " + fds.quoteTable() + " ADD COLUMN " + fds.columnDef(i),
+			})
+			continue
+		}
+
+		actual := strings.ToUpper(ct.DatabaseTypeName())
+		if len(actual) == 0 || fds.compatibleType(i, actual) {
+			continue
+		}
+		issues = append(issues, SchemaIssue{
+			Column:   fds.fields[i].Tag,
+			Expected: fds.columnType(i),
+			Actual:   actual,
+			Fix:      fds.modifyColumnSQL(i),
+		})
+	}
+
+	if len(issues) > 0 {
+		return &SchemaError{Table: fds.table, Issues: issues}
+	}
+
+	return nil
+}
+
+// modifyColumnSQL ALTER changing column type of field idx by dialect
+// example:"ALTER TABLE `t` MODIFY COLUMN `field_thr` BIGINT"
+// Postgres:"ALTER TABLE \"t\" ALTER COLUMN \"field_thr\" TYPE BIGINT USING \"field_thr\"::BIGINT"
+func (fds *_FieldsMap) modifyColumnSQL(idx int) string {
+
+	if _, pg := fds.dialect.(postgresDialect); pg {
+		col, typ := fds.quote(fds.fields[idx].Tag), fds.columnType(idx)
+		return "ALTER TABLE " + fds.quoteTable() + " ALTER COLUMN " + col +
+			" TYPE " + typ + " USING " + col + "::" + typ
+	}
+
+	return "ALTER TABLE " + fds.quoteTable() + " MODIFY COLUMN " + fds.columnDef(idx)
+}
+
+// compatibleType column of db type can hold field idx
+func (fds *_FieldsMap) compatibleType(idx int, dbType string) bool {
+
+	family := typeFamily(dbType)
+	switch baseType(fds.fields[idx].Type) {
+	case "int64", "enum":
+		return family == "int"
+	case "uint64":
+		// NUMERIC(20) on Postgres, see columnType
+		name := typeName(dbType)
+		return family == "int" || name == "NUMERIC" || name == "DECIMAL"
+	case "string":
+		return family == "string"
+	case "float64":
+		return family == "float" || family == "int"
+	case "bool":
+		return family == "int" || family == "bool"
+	case "time.Time":
+		if len(fds.fields[idx].epoch) > 0 {
+			return family == "int"
+		}
+		if fds.timeAsString(idx) {
+			return family == "time" || family == "string"
+		}
+		return family == "time"
+	case "scanner":
+		// any column the type can Scan
+		return true
+	default:
+	}
+
+	return false
+}
+
+// typeName db type name without size & modifiers
+// example: typeName("NUMERIC(20)") returns "NUMERIC"
+func typeName(dbType string) string {
+
+	name := strings.Fields(dbType)[0]
+	if i := strings.Index(name, "("); i >= 0 {
+		name = name[:i]
+	}
+
+	return name
+}
+
+// typeFamily family of db type name: int, float, bool, string, time
+func typeFamily(dbType string) string {
+
+	name := typeName(dbType)
+	switch name {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT",
+		"INT2", "INT4", "INT8", "SERIAL", "BIGSERIAL", "UNSIGNED":
+		return "int"
+	case "FLOAT", "DOUBLE", "REAL", "DECIMAL", "NUMERIC", "FLOAT4", "FLOAT8":
+		return "float"
+	case "BOOL", "BOOLEAN", "BIT":
+		return "bool"
+	case "CHAR", "VARCHAR", "TEXT", "TINYTEXT", "MEDIUMTEXT", "LONGTEXT",
+		"ENUM", "SET", "JSON", "BPCHAR", "UUID":
+		return "string"
+	case "DATETIME", "TIMESTAMP", "DATE", "TIMESTAMPTZ":
+		return "time"
+	default:
+	}
+
+	return name
+}
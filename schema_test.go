@@ -0,0 +1,114 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSQLValidateSchema(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols:  []string{"field_key", "field_one", "field_two", "field_thr"},
+			types: []string{"VARCHAR", "TEXT", "TINYINT", "VARCHAR"},
+		}
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	err := fm.SQLValidateSchema(context.Background(), nil, db)
+	var serr *SchemaError
+	if !errors.As(err, &serr) {
+		t.Fatalf("want *SchemaError, got %v", err)
+	}
+	if len(serr.Issues) != 2 {
+		t.Fatalf("got issues %v", serr.Issues)
+	}
+
+	want := SchemaIssue{Column: "field_thr", Expected: "BIGINT", Actual: "VARCHAR",
+		Fix: "ALTER TABLE `test_table` MODIFY COLUMN `field_thr` BIGINT"}
+	if serr.Issues[0] != want {
+		t.Errorf("got %+v\nwant %+v", serr.Issues[0], want)
+	}
+	want = SchemaIssue{Column: "field_fou", Expected: "DOUBLE",
+		Fix: "ALTER TABLE `test_table` ADD COLUMN `field_fou` DOUBLE"}
+	if serr.Issues[1] != want {
+		t.Errorf("got %+v\nwant %+v", serr.Issues[1], want)
+	}
+
+	if len(fdb.Queries()) != 1 {
+		t.Error("validate must be read-only")
+	}
+
+	pg, _ := NewFieldsMapWithDialect(table, &row, Postgres)
+	err = pg.SQLValidateSchema(context.Background(), nil, db)
+	if !errors.As(err, &serr) || len(serr.Issues) != 2 {
+		t.Fatalf("want *SchemaError of 2 issues, got %v", err)
+	}
+	want = SchemaIssue{Column: "field_thr", Expected: "BIGINT", Actual: "VARCHAR",
+		Fix: `ALTER TABLE "test_table" ALTER COLUMN "field_thr" TYPE BIGINT USING "field_thr"::BIGINT`}
+	if serr.Issues[0] != want {
+		t.Errorf("got %+v\nwant %+v", serr.Issues[0], want)
+	}
+	want = SchemaIssue{Column: "field_fou", Expected: "DOUBLE PRECISION",
+		Fix: `ALTER TABLE "test_table" ADD COLUMN "field_fou" DOUBLE PRECISION`}
+	if serr.Issues[1] != want {
+		t.Errorf("got %+v\nwant %+v", serr.Issues[1], want)
+	}
+}
+
+func TestSQLValidateSchemaOK(t *testing.T) {
+
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols:  []string{"field_key", "field_one", "field_two", "field_thr", "field_fou"},
+			types: []string{"VARCHAR", "CHAR", "BOOL", "INT", "DECIMAL"},
+		}
+	})
+	defer db.Close()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+	if err := fm.SQLValidateSchema(context.Background(), nil, db); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSQLValidateSchemaCreated(t *testing.T) {
+
+	type userRow struct {
+		ID      int64      `sql:"id,pk,auto"`
+		Name    string     `sql:"name"`
+		Balance float64    `sql:"balance"`
+		Count   uint64     `sql:"count"`
+		Active  bool       `sql:"active"`
+		Born    time.Time  `sql:"born"`
+		Left    *time.Time `sql:"left_at"`
+	}
+
+	// type names as lib/pq reports the columns created by SQLCreateTable
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "CREATE") {
+			return &fakeResult{}
+		}
+		return &fakeResult{
+			cols:  []string{"id", "name", "balance", "count", "active", "born", "left_at"},
+			types: []string{"INT8", "VARCHAR", "FLOAT8", "NUMERIC", "BOOL", "TIMESTAMP", "TIMESTAMP"},
+		}
+	})
+	defer db.Close()
+
+	fm, _ := NewFieldsMapWithDialect("users", &userRow{}, Postgres)
+	if err := fm.SQLCreateTable(context.Background(), nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if err := fm.SQLValidateSchema(context.Background(), nil, db); err != nil {
+		t.Error(err)
+	}
+}
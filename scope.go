@@ -0,0 +1,137 @@
+package sqlmapper
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var (
+	// whereRe WHERE keyword in extStr
+	whereRe = regexp.MustCompile(`(?i)\bwhere\b`)
+	// tailRe first clause after the WHERE condition in extStr
+	tailRe = regexp.MustCompile(`(?i)\b(group\s+by|having|order\s+by|limit|for\s+update|lock\s+in\s+share\s+mode)\b`)
+)
+
+// scope mandatory column = value of all statements
+type scope struct {
+	idx   int
+	value interface{}
+}
+
+// SetScope scope all statements by column nameInDB = value, e.g. tenant_id:
+// SELECT/UPDATE/DELETE get `table`.`nameInDB` = ? ANDed to their WHERE,
+// INSERT/UPDATE set the field to value before bind.
+// value must be assignable to the field, an int may be given for any
+// signed integer field it fits in.
+// extStr is rewritten as " where `t`.`c` = ? AND (cond) order by ... ",
+// so its WHERE condition must be before GROUP BY/ORDER BY/LIMIT etc.
+// PrepareStmt, SQL*Stmt & SQLRaw* are not scoped
+// example: fds.SetScope("tenant_id", tenantID)
+func (fds *_FieldsMap) SetScope(nameInDB string, value interface{}) error {
+
+	idx := fds.fieldIndex(nameInDB)
+	if idx < 0 {
+		return errors.New("no field match `sql` tag:" + nameInDB)
+	}
+
+	ft := reflect.TypeOf(fds.fields[idx].Addr).Elem()
+	vv, ok := assignValue(ft, value)
+	if !ok {
+		return errors.New("scope value is not " + ft.String() + ": " + nameInDB)
+	}
+
+	fds.scope = &scope{idx: idx, value: vv.Interface()}
+	return nil
+}
+
+// assignValue value as type t if assignable to it, or an int widened
+// to signed integer type t without overflow, no other conversion is done
+// so that e.g. int to string (rune) or float to int (truncate) fail
+func assignValue(t reflect.Type, value interface{}) (reflect.Value, bool) {
+
+	vv := reflect.ValueOf(value)
+	if !vv.IsValid() {
+		return vv, false
+	}
+	if vv.Type().AssignableTo(t) {
+		return vv, true
+	}
+
+	if vv.Type() != reflect.TypeOf(0) {
+		return vv, false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if reflect.Zero(t).OverflowInt(vv.Int()) {
+			return vv, false
+		}
+		return vv.Convert(t), true
+	}
+
+	return vv, false
+}
+
+// scoped add scope condition to extStr and its value to args,
+// and soft delete condition unless SetWithTrashed,
+// extStr & args are returned as is without them
+func (fds *_FieldsMap) scoped(extStr string, args ...interface{}) (string, []interface{}) {
+
+	return fds.scopedBy(extStr, !fds.withTrashed, args...)
+}
+
+// scopedBy scoped, with soft delete condition if live
+func (fds *_FieldsMap) scopedBy(extStr string, live bool,
+	args ...interface{}) (string, []interface{}) {
+
+	var conds []string
+	var condArgs []interface{}
+	if fds.scope != nil {
+		conds = append(conds, fds.quoteTable()+"."+fds.quote(fds.fields[fds.scope.idx].Tag)+" = ?")
+		condArgs = append(condArgs, fds.scope.value)
+	}
+	if idx := fds.softDeleteIndex(); idx >= 0 && live {
+		conds = append(conds, fds.quoteTable()+"."+fds.quote(fds.fields[idx].Tag)+" IS NULL")
+	}
+	if len(conds) == 0 {
+		return extStr, args
+	}
+
+	cond := strings.Join(conds, " AND ")
+
+	pos := len(extStr)
+	if loc := tailRe.FindStringIndex(extStr); loc != nil {
+		pos = loc[0]
+	}
+
+	var newExt string
+	var at int
+	if loc := whereRe.FindStringIndex(extStr); loc != nil && loc[0] < pos {
+		newExt = extStr[:loc[0]] + "where " + cond + " AND (" +
+			extStr[loc[1]:pos] + ") " + extStr[pos:]
+		at = strings.Count(extStr[:loc[0]], "?")
+	} else {
+		newExt = extStr[:pos] + " where " + cond + " " + extStr[pos:]
+		at = strings.Count(extStr[:pos], "?")
+	}
+
+	if at > len(args) {
+		at = len(args)
+	}
+	newArgs := make([]interface{}, 0, len(args)+len(condArgs))
+	newArgs = append(newArgs, args[:at]...)
+	newArgs = append(newArgs, condArgs...)
+	newArgs = append(newArgs, args[at:]...)
+
+	return newExt, newArgs
+}
+
+// fillScope set scope field to scope value
+func (fds *_FieldsMap) fillScope() {
+
+	if fds.scope != nil {
+		v := reflect.ValueOf(fds.fields[fds.scope.idx].Addr).Elem()
+		v.Set(reflect.ValueOf(fds.scope.value))
+	}
+}
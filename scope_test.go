@@ -0,0 +1,144 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+type tenantRow struct {
+	ID       string `sql:"id"`
+	TenantID int64  `sql:"tenant_id"`
+	Name     string `sql:"name"`
+}
+
+func TestScoped(t *testing.T) {
+
+	fm, _ := NewFieldsMap("orders", &tenantRow{})
+	if err := fm.SetScope("tenant_id", 7); err != nil {
+		t.Fatal(err)
+	}
+	fds := fm.(*_FieldsMap)
+
+	cases := []struct {
+		extStr string
+		args   []interface{}
+		want   string
+		wargs  string
+	}{
+		{"", nil, " where `orders`.`tenant_id` = ? ", "[7]"},
+		{" where `name` = ? or `id` = ? ", []interface{}{"a", "b"},
+			" where `orders`.`tenant_id` = ? AND ( `name` = ? or `id` = ? ) ", "[7 a b]"},
+		{" WHERE `name` > ? ORDER BY `id` LIMIT ? ", []interface{}{"a", 10},
+			" where `orders`.`tenant_id` = ? AND ( `name` > ? ) ORDER BY `id` LIMIT ? ", "[7 a 10]"},
+		{" order by `id` limit ? ", []interface{}{10},
+			"  where `orders`.`tenant_id` = ? order by `id` limit ? ", "[7 10]"},
+		{" JOIN `k` ON `k`.`v` = ? where `name` = ? ", []interface{}{1, "a"},
+			" JOIN `k` ON `k`.`v` = ? where `orders`.`tenant_id` = ? AND ( `name` = ? ) ", "[1 7 a]"},
+	}
+	for _, c := range cases {
+		got, args := fds.scoped(c.extStr, c.args...)
+		if got != c.want || fmt.Sprint(args) != c.wargs {
+			t.Errorf("scoped(%q)\ngot  %q %v\nwant %q %s", c.extStr, got, args, c.want, c.wargs)
+		}
+	}
+}
+
+func TestSetScope(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "tenant_id", "name"},
+			rows: [][]driver.Value{{"id001", int64(7), "one"}},
+		}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := tenantRow{ID: "id001", TenantID: 8, Name: "one"}
+	fm, _ := NewFieldsMap("orders", &row)
+	if err := fm.SetScope("tenant_id", 7); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.args[1] != int64(7) || row.TenantID != 7 {
+		t.Errorf("insert should set scope, got %v", q.args)
+	}
+
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT  `id`, `tenant_id`, `name`  FROM `orders`  where `orders`.`tenant_id` = ? AND ( `id` = ? ) "
+	if q := fdb.LastQuery(); q.sql != want || fmt.Sprint(q.args) != "[7 id001]" {
+		t.Errorf("got %q %v\nwant %q", q.sql, q.args, want)
+	}
+
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	want = "UPDATE `orders` SET  `id` = ?, `tenant_id` = ?, `name` = ?  where `orders`.`tenant_id` = ? AND ( `id` = ? ) "
+	if q := fdb.LastQuery(); q.sql != want || fmt.Sprint(q.args) != "[id001 7 one 7 id001]" {
+		t.Errorf("got %q %v\nwant %q", q.sql, q.args, want)
+	}
+
+	if err := fm.SQLDeleteByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); fmt.Sprint(q.args) != "[7 id001]" {
+		t.Errorf("delete got %q %v", q.sql, q.args)
+	}
+
+	other := tenantRow{ID: "id002", TenantID: 9}
+	if _, err := fm.SQLUpdateManyByPriKey(ctx, nil, db, []interface{}{&row, &other}); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); fmt.Sprint(q.args) != "[id002 7  7 id002]" {
+		t.Errorf("update many got %v", q.args)
+	}
+
+	if err := fm.SetScope("org_id", 7); err == nil {
+		t.Error("want error for unknown scope column")
+	}
+	if err := fm.SetScope("tenant_id", "seven"); err == nil {
+		t.Error("want error for scope value of wrong type")
+	}
+	if err := fm.SetScope("tenant_id", 7.9); err == nil {
+		t.Error("want error for float scope value on int field")
+	}
+}
+
+func TestSetScopeNoConvert(t *testing.T) {
+
+	type strTenant struct {
+		ID       string `sql:"id"`
+		TenantID string `sql:"tenant_id"`
+	}
+	type smallTenant struct {
+		ID       string `sql:"id"`
+		TenantID int8   `sql:"tenant_id"`
+	}
+
+	fm, _ := NewFieldsMap("orders", &strTenant{})
+	if err := fm.SetScope("tenant_id", 42); err == nil {
+		t.Errorf("want error for int scope value on string field, got scope %v",
+			fm.(*_FieldsMap).scope.value)
+	}
+	if err := fm.SetScope("tenant_id", "42"); err != nil {
+		t.Error(err)
+	}
+
+	fm, _ = NewFieldsMap("orders", &smallTenant{})
+	if err := fm.SetScope("tenant_id", 300); err == nil {
+		t.Error("want error for int scope value overflowing int8")
+	}
+	if err := fm.SetScope("tenant_id", 42); err != nil {
+		t.Error(err)
+	}
+	if v := fm.(*_FieldsMap).scope.value; v != int8(42) {
+		t.Errorf("got scope %#v want int8(42)", v)
+	}
+}
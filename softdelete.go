@@ -0,0 +1,65 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"time"
+)
+
+// softDeleteIndex index of field with `softdelete` tag option, -1 if none
+func (fds *_FieldsMap) softDeleteIndex() int {
+
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if fds.fields[i].soft {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// SetWithTrashed with softdelete field, SELECT/UPDATE/DELETE see
+// soft deleted rows too when on, only rows of NULL softdelete field
+// when off (default)
+func (fds *_FieldsMap) SetWithTrashed(on bool) {
+
+	fds.withTrashed = on
+}
+
+// SQLForceDeleteByPriKey DELETE row of primary key, also a trashed one,
+// SQLDeleteByPriKey for struct without softdelete field
+func (fds *_FieldsMap) SQLForceDeleteByPriKey(ctx context.Context, tx *sql.Tx,
+	db *sql.DB) error {
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = fds.deleteByPriKey(ctx, exec, true)
+	return err
+}
+
+// softDelete set softdelete field idx to now in db and Object(struct)
+// UPDATE `t` SET `deleted_at` = ? where `id` = ?
+func (fds *_FieldsMap) softDelete(ctx context.Context, exec Executor,
+	idx int) (sql.Result, error) {
+
+	now := time.Now()
+	if fds.now != nil {
+		now = fds.now()
+	}
+
+	extStr, args := fds.scoped(" where "+fds.priKeyCond()+" ", fds.priKeyValues()...)
+	values := append([]interface{}{fds.bindValue(idx, now)}, args...)
+	sqlstr := fds.verb("UPDATE") + fds.quoteTable() + " SET " +
+		fds.quote(fds.fields[idx].Tag) + " = ?" + extStr
+	res, err := fds.execSQL(ctx, exec, sqlstr, values...)
+	if err != nil {
+		return nil, err
+	}
+
+	reflect.ValueOf(fds.fields[idx].Addr).Elem().Set(reflect.ValueOf(&now))
+	return res, nil
+}
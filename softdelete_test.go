@@ -0,0 +1,116 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+type trashRow struct {
+	ID        int64      `sql:"id"`
+	Name      string     `sql:"name"`
+	DeletedAt *time.Time `sql:"deleted_at,softdelete"`
+}
+
+func TestSoftDelete(t *testing.T) {
+
+	// a tiny table of two rows, the fake driver applies IS NULL filter
+	deleted := map[int64]interface{}{1: nil, 2: nil}
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		switch {
+		case strings.HasPrefix(q, "UPDATE"):
+			deleted[args[1].(int64)] = args[0]
+			return &fakeResult{affected: 1}
+		case strings.HasPrefix(q, "DELETE"):
+			delete(deleted, args[0].(int64))
+			return &fakeResult{affected: 1}
+		default:
+		}
+		res := &fakeResult{cols: []string{"id", "name", "deleted_at"}}
+		for _, id := range []int64{1, 2} {
+			at, ok := deleted[id]
+			if !ok || (at != nil && strings.Contains(q, "IS NULL")) {
+				continue
+			}
+			res.rows = append(res.rows, []driver.Value{id, "row", at})
+		}
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	clock := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	row := trashRow{ID: 1}
+	fm, err := NewFieldsMap("trash_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm.SetClock(func() time.Time { return clock })
+
+	if err := fm.SQLDeleteByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	q := fdb.LastQuery()
+	if q.sql != "UPDATE `trash_table` SET `deleted_at` = ? where `trash_table`.`deleted_at` IS NULL AND ( `id` = ? ) " {
+		t.Errorf("unexpected soft delete %q", q.sql)
+	}
+	if row.DeletedAt == nil || !row.DeletedAt.Equal(clock) {
+		t.Errorf("deleted_at not set: %v", row.DeletedAt)
+	}
+
+	objs, err := fm.SQLSelectAllRows(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 1 || objs[0].(*trashRow).ID != 2 {
+		t.Errorf("soft deleted row selected: %v", objs)
+	}
+
+	fm.SetWithTrashed(true)
+	objs, err = fm.SQLSelectAllRows(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 || objs[0].(*trashRow).DeletedAt == nil {
+		t.Errorf("want trashed row with trashed, got %v", objs)
+	}
+	fm.SetWithTrashed(false)
+
+	if err := fm.SQLForceDeleteByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.sql != "DELETE FROM `trash_table`  where `id` = ? " {
+		t.Errorf("unexpected force delete %q", q.sql)
+	}
+	if _, ok := deleted[1]; ok {
+		t.Error("row not deleted by force")
+	}
+}
+
+func TestSoftDeleteByPriKeys(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{affected: 2}
+	})
+	defer db.Close()
+
+	clock := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var row trashRow
+	fm, _ := NewFieldsMap("trash_table", &row)
+	fm.SetClock(func() time.Time { return clock })
+
+	n, err := fm.SQLDeleteByPriKeys(context.Background(), nil, db, []interface{}{int64(1), int64(2)})
+	if err != nil || n != 2 {
+		t.Fatalf("got %d %v", n, err)
+	}
+	q := fdb.LastQuery()
+	want := "UPDATE `trash_table` SET `deleted_at` = ? where `trash_table`.`deleted_at` IS NULL AND ( `id` IN (?, ?) ) "
+	if q.sql != want || len(q.args) != 3 || q.args[1] != int64(1) {
+		t.Errorf("got %q %v", q.sql, q.args)
+	}
+	if at, ok := q.args[0].(time.Time); !ok || !at.Equal(clock) {
+		t.Errorf("deleted_at should be bound first, got %v", q.args[0])
+	}
+}
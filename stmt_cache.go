@@ -0,0 +1,231 @@
+package sqlmapper
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+)
+
+// StmtCacheStats counters of the statement cache, see SetStmtCache
+type StmtCacheStats struct {
+	Hits      uint64 // statements reused from cache
+	Misses    uint64 // statements prepared and cached
+	Evictions uint64 // least recently used statements closed
+	Size      int    // statements in cache
+}
+
+// stmtCache LRU of statements prepared on one *sql.DB, keyed by SQL
+type stmtCache struct {
+	hits      uint64 // atomic, first for 64-bit alignment
+	misses    uint64
+	evictions uint64
+
+	mu      sync.Mutex
+	db      *sql.DB
+	size    int
+	closed  bool // statements are no longer cached
+	lru     *list.List // of *cachedStmt, most recent at front
+	entries map[string]*list.Element
+}
+
+// cachedStmt statement in cache, closed when evicted and not in use
+type cachedStmt struct {
+	sqlstr  string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+// SetStmtCache keep up to size statements prepared on db by fds
+// for reuse instead of preparing each call, least recently used are closed,
+// statements on tx are not cached, see StmtCacheStats.
+// 0 size disables cache and closes cached statements, off by default.
+// the cache lives as long as fds, see NewMapperWithStmtCache to share one
+// by calls making a new FieldsMap each
+// example: fds.SetStmtCache(64)
+func (fds *_FieldsMap) SetStmtCache(size int) {
+
+	old := fds.stmts
+	fds.stmts = newStmtCache(size)
+
+	if old != nil {
+		old.closeAll()
+	}
+}
+
+// StmtCacheStats counters of the statement cache, zero if disabled
+func (fds *_FieldsMap) StmtCacheStats() StmtCacheStats {
+
+	return fds.stmts.stats()
+}
+
+// newStmtCache cache of up to size statements, nil if size <= 0
+func newStmtCache(size int) *stmtCache {
+
+	if size <= 0 {
+		return nil
+	}
+
+	return &stmtCache{size: size, lru: list.New(), entries: map[string]*list.Element{}}
+}
+
+// stats counters of c, zero if c is nil
+func (c *stmtCache) stats() StmtCacheStats {
+
+	if c == nil {
+		return StmtCacheStats{}
+	}
+
+	c.mu.Lock()
+	n := c.lru.Len()
+	c.mu.Unlock()
+
+	return StmtCacheStats{
+		Hits:      atomic.LoadUint64(&c.hits),
+		Misses:    atomic.LoadUint64(&c.misses),
+		Evictions: atomic.LoadUint64(&c.evictions),
+		Size:      n,
+	}
+}
+
+// prepareShared prepare sqlstr on exec, from cache when exec is the *sql.DB
+// of the cache, release must be called instead of closing the statement
+func (fds *_FieldsMap) prepareShared(ctx context.Context, exec Executor,
+	sqlstr string) (stmt *sql.Stmt, release func(), err error) {
+
+	c := fds.stmts
+	db, ok := exec.(*sql.DB)
+	if c == nil || !ok {
+		stmt, err = fds.prepare(ctx, exec, sqlstr)
+		if err != nil {
+			return nil, nil, err
+		}
+		return stmt, func() { stmt.Close() }, nil
+	}
+
+	if cs := c.acquire(db, sqlstr); cs != nil {
+		atomic.AddUint64(&c.hits, 1)
+		return cs.stmt, func() { c.release(cs) }, nil
+	}
+
+	stmt, err = fds.prepare(ctx, exec, sqlstr)
+	if err != nil {
+		return nil, nil, err
+	}
+	atomic.AddUint64(&c.misses, 1)
+
+	cs := c.add(db, sqlstr, stmt)
+	return cs.stmt, func() { c.release(cs) }, nil
+}
+
+// acquire cached statement of sqlstr on db, nil if not cached
+func (c *stmtCache) acquire(db *sql.DB, sqlstr string) *cachedStmt {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.db != db {
+		return nil
+	}
+
+	e, ok := c.entries[sqlstr]
+	if !ok {
+		return nil
+	}
+
+	c.lru.MoveToFront(e)
+	cs := e.Value.(*cachedStmt)
+	cs.refs++
+	return cs
+}
+
+// add cache stmt in use, evict least recently used over size,
+// statements of another db are dropped first
+func (c *stmtCache) add(db *sql.DB, sqlstr string, stmt *sql.Stmt) *cachedStmt {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		// closed meanwhile, statement is closed by release
+		return &cachedStmt{sqlstr: sqlstr, stmt: stmt, refs: 1, evicted: true}
+	}
+
+	if c.db != db {
+		c.evictAll()
+		c.db = db
+	}
+
+	if e, ok := c.entries[sqlstr]; ok {
+		// prepared concurrently, keep the cached one
+		stmt.Close()
+		c.lru.MoveToFront(e)
+		cs := e.Value.(*cachedStmt)
+		cs.refs++
+		return cs
+	}
+
+	cs := &cachedStmt{sqlstr: sqlstr, stmt: stmt, refs: 1}
+	c.entries[sqlstr] = c.lru.PushFront(cs)
+
+	for c.lru.Len() > c.size {
+		c.evict(c.lru.Back())
+		atomic.AddUint64(&c.evictions, 1)
+	}
+
+	return cs
+}
+
+// release statement after use, close it if evicted meanwhile
+func (c *stmtCache) release(cs *cachedStmt) {
+
+	c.mu.Lock()
+	cs.refs--
+	closing := cs.evicted && cs.refs == 0
+	c.mu.Unlock()
+
+	if closing {
+		cs.stmt.Close()
+	}
+}
+
+// evict remove e from cache, close its statement if not in use,
+// must hold c.mu
+func (c *stmtCache) evict(e *list.Element) error {
+
+	cs := c.lru.Remove(e).(*cachedStmt)
+	delete(c.entries, cs.sqlstr)
+	cs.evicted = true
+	if cs.refs == 0 {
+		return cs.stmt.Close()
+	}
+
+	return nil
+}
+
+// evictAll evict every statement, first error of closing them,
+// must hold c.mu
+func (c *stmtCache) evictAll() error {
+
+	var first error
+	for c.lru.Len() > 0 {
+		if err := c.evict(c.lru.Back()); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// closeAll evict every statement and stop caching, statements in use
+// are closed when released
+func (c *stmtCache) closeAll() error {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	return c.evictAll()
+}
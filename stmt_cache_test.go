@@ -0,0 +1,124 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+)
+
+func TestSetStmtCache(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "key001"}
+	fm, _ := NewFieldsMap(table, &row)
+	if s := fm.StmtCacheStats(); s != (StmtCacheStats{}) {
+		t.Errorf("cache should be off by default, got %+v", s)
+	}
+
+	fm.SetStmtCache(2)
+	for i := 0; i < 3; i++ {
+		if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := fm.StmtCacheStats(); s.Hits != 2 || s.Misses != 1 || s.Size != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	fm.SQLDeleteByPriKey(ctx, nil, db)
+	fm.SQLInsert(ctx, nil, db)
+	fm.SQLUpdateByPriKey(ctx, nil, db)
+	if s := fm.StmtCacheStats(); s.Misses != 4 || s.Evictions != 2 || s.Size != 2 {
+		t.Errorf("unexpected stats after eviction %+v", s)
+	}
+
+	n := len(fdb.Prepares())
+	fm.SQLInsert(ctx, nil, db)
+	if len(fdb.Prepares()) != n {
+		t.Error("cached INSERT should not be prepared again")
+	}
+
+	tx, _ := db.BeginTx(ctx, nil)
+	fm.SQLInsert(ctx, tx, nil)
+	tx.Commit()
+	if s := fm.StmtCacheStats(); s.Hits != 3 || s.Misses != 4 {
+		t.Errorf("statements on tx should not be cached, got %+v", s)
+	}
+
+	fm.SetStmtCache(0)
+	if s := fm.StmtCacheStats(); s != (StmtCacheStats{}) {
+		t.Errorf("disabled cache should have zero stats, got %+v", s)
+	}
+}
+
+func TestStmtCacheConcurrent(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return demoRowsResult(2)
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+	fm.SetStmtCache(1)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if _, err := fm.SQLSelectAllRows(ctx, nil, db); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if s := fm.StmtCacheStats(); s.Hits+s.Misses != 400 || s.Size != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if n := len(fdb.Prepares()); n > 8 {
+		t.Errorf("got %d prepares, want at most one per goroutine", n)
+	}
+}
+
+func BenchmarkSelectByPriKeyCached(b *testing.B) {
+
+	benchmarkSelectByPriKey(b, 16)
+}
+
+func BenchmarkSelectByPriKeyUncached(b *testing.B) {
+
+	benchmarkSelectByPriKey(b, 0)
+}
+
+func benchmarkSelectByPriKey(b *testing.B, cacheSize int) {
+
+	res := demoRowsResult(1)
+	db, _ := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "keya"}
+	fm, _ := NewFieldsMap(table, &row)
+	fm.SetStmtCache(cacheSize)
+	defer fm.SetStmtCache(0)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
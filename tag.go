@@ -0,0 +1,103 @@
+package sqlmapper
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// tagOptions options after column name in `sql` tag,
+// values may be single quoted to hold commas
+// example: `sql:"ts,epoch=ms"` => {"epoch": "ms"}
+type tagOptions map[string]string
+
+// parseTag split `sql` tag into column name and options
+func parseTag(tag string) (string, tagOptions) {
+
+	parts := splitTag(tag)
+	opts := tagOptions{}
+	for i, plen := 1, len(parts); i < plen; i++ {
+		kv := strings.SplitN(parts[i], "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if len(key) == 0 {
+			continue
+		}
+		if len(kv) == 1 {
+			opts[key] = ""
+			continue
+		}
+		value := strings.TrimSpace(kv[1])
+		if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+		opts[key] = value
+	}
+
+	return strings.TrimSpace(parts[0]), opts
+}
+
+// lookupTag value of the first of tagKeys tags present & not empty in st,
+// "" if none
+// example: lookupTag(`db:"name" json:"name,omitempty"`, []string{"sql", "db"}) => "name"
+func lookupTag(st reflect.StructTag, tagKeys []string) string {
+
+	for i, klen := 0, len(tagKeys); i < klen; i++ {
+		if tag := st.Get(tagKeys[i]); len(tag) > 0 {
+			return tag
+		}
+	}
+
+	return ""
+}
+
+// splitTag split tag by commas out of single quotes
+func splitTag(tag string) []string {
+
+	var parts []string
+	quoted := false
+	start := 0
+	for i, tlen := 0, len(tag); i < tlen; i++ {
+		switch tag[i] {
+		case '\'':
+			quoted = !quoted
+			break
+		case ',':
+			if !quoted {
+				parts = append(parts, tag[start:i])
+				start = i + 1
+			}
+			break
+		default:
+		}
+	}
+
+	return append(parts, tag[start:])
+}
+
+// Has option is set
+func (opts tagOptions) Has(key string) bool {
+
+	_, ok := opts[key]
+	return ok
+}
+
+// snakeCase column name of a field without `sql` tag,
+// example: FieldKey => field_key, UserID => user_id, HTTPServer => http_server
+func snakeCase(name string) string {
+
+	runes := []rune(name)
+	var b strings.Builder
+	for i, rlen := 0, len(runes); i < rlen; i++ {
+		r := runes[i]
+		if unicode.IsUpper(r) {
+			if i > 0 && (!unicode.IsUpper(runes[i-1]) ||
+				(i+1 < rlen && unicode.IsLower(runes[i+1]))) && runes[i-1] != '_' {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
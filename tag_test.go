@@ -0,0 +1,139 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+
+	name, opts := parseTag("field_key")
+	if name != "field_key" || len(opts) != 0 {
+		t.Errorf("got %q %v", name, opts)
+	}
+
+	name, opts = parseTag("ts,epoch=ms")
+	if name != "ts" || opts["epoch"] != "ms" {
+		t.Errorf("got %q %v", name, opts)
+	}
+
+	name, opts = parseTag("status, flag ,note='a, b ''c'''")
+	if name != "status" || !opts.Has("flag") || opts["note"] != "a, b 'c'" {
+		t.Errorf("got %q %v", name, opts)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+
+	cases := map[string]string{
+		"FieldKey":   "field_key",
+		"ID":         "id",
+		"UserID":     "user_id",
+		"HTTPServer": "http_server",
+		"Field_One":  "field_one",
+		"V2Name":     "v2_name",
+	}
+	for name, want := range cases {
+		if got := snakeCase(name); got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestUntaggedFields(t *testing.T) {
+
+	var row struct {
+		FieldKey string `sql:",pk"`
+		FieldOne string
+		UserID   int64
+	}
+	fm, err := NewFieldsMap("untagged_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := fm.SQLFieldsStr(); s != " `field_key`, `field_one`, `user_id` " {
+		t.Errorf("unexpected fields %q", s)
+	}
+}
+
+type dbTagRow struct {
+	Key   string  `db:"k,pk" sql:"ignored"`
+	Name  string  `db:"name"`
+	Flag  bool    `db:"flag"`
+	Count int64   `db:"cnt"`
+	Score float64 `db:"score"`
+	Memo  string  `db:"-"`
+}
+
+func TestNewFieldsMapWithTagKey(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		res := demoRowsResult(2)
+		res.cols = []string{"k", "name", "flag", "cnt", "score"}
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := dbTagRow{Key: "k1"}
+	fm, err := NewFieldsMapWithTagKey("db_table", &row, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := fm.SQLFieldsStr(); s != " `k`, `name`, `flag`, `cnt`, `score` " {
+		t.Errorf("unexpected fields %q", s)
+	}
+
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); !strings.HasSuffix(q.sql, " where `k` = ? ") {
+		t.Errorf("pk option should come from db tag, got %q", q.sql)
+	}
+	if row.Key != "keya" || row.Name != "one" || !row.Flag {
+		t.Errorf("unexpected row %+v", row)
+	}
+
+	objs, err := fm.SQLRawSelectByName(ctx, nil, db, "SELECT * FROM `db_table`")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 || objs[1].(*dbTagRow).Count != 1 {
+		t.Errorf("rows should map by db tag, got %+v", objs)
+	}
+
+	sqlfm, _ := NewFieldsMap("db_table", &row)
+	if s := sqlfm.SQLFieldsStr(); !strings.HasPrefix(s, " `ignored`, `name`, ") {
+		t.Errorf("NewFieldsMap should still read sql tags, got %q", s)
+	}
+}
+
+func TestNewFieldsMapWithTagKeys(t *testing.T) {
+
+	var row struct {
+		ID      int64  `sql:"id,pk" db:"user_id"`
+		Name    string `db:"user_name" json:"name"`
+		Email   string `json:"email,omitempty"`
+		Phone   string `json:",omitempty"`
+		Nick    string
+		Secret  string `db:"-" json:"secret"`
+		Comment string `sql:"" db:"remark"`
+	}
+	fm, err := NewFieldsMapWithTagKeys("users", &row, "sql", "db", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := fm.SQLFieldsStr(); s != " `id`, `user_name`, `email`, `phone`, `nick`, `remark` " {
+		t.Errorf("unexpected fields %q", s)
+	}
+	if fm.GetPriKeyIndex() != 0 {
+		t.Errorf("pk option of sql tag lost, got %d", fm.GetPriKeyIndex())
+	}
+
+	json, _ := NewFieldsMapWithTagKeys("users", &row, "json")
+	if s := json.SQLFieldsStr(); s != " `id`, `name`, `email`, `phone`, `nick`, `secret`, `comment` " {
+		t.Errorf("unexpected fields by json %q", s)
+	}
+}
@@ -0,0 +1,37 @@
+package sqlmapper
+
+import (
+	"time"
+)
+
+// SetTimeLayout bind time.Time fields as strings formatted by layout (in UTC)
+// and parse scanned strings back by layout, for drivers that can not
+// bind time.Time, empty layout leaves time.Time to the driver (default).
+// fields with epoch tag option are not affected
+// example: fds.SetTimeLayout("2006-01-02 15:04:05")
+func (fds *_FieldsMap) SetTimeLayout(layout string) {
+
+	fds.timeLayout = layout
+}
+
+// timeAsString time.Time field idx is bound & scanned as string
+func (fds *_FieldsMap) timeAsString(idx int) bool {
+
+	return len(fds.timeLayout) > 0 && len(fds.fields[idx].epoch) == 0
+}
+
+// formatTime bind value of time.Time by timeLayout, zero time.Time is NULL
+func (fds *_FieldsMap) formatTime(t time.Time) interface{} {
+
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.UTC().Format(fds.timeLayout)
+}
+
+// parseTime scanned string of time.Time by timeLayout
+func (fds *_FieldsMap) parseTime(s string) (time.Time, error) {
+
+	return time.ParseInLocation(fds.timeLayout, s, time.UTC)
+}
@@ -0,0 +1,55 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestSetTimeLayout(t *testing.T) {
+
+	const layout = "2006-01-02 15:04:05"
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "created", "seen_ms", "seen_s"},
+			rows: [][]driver.Value{{int64(1), "2024-05-06 07:08:09", now.UnixMilli(), nil}},
+		}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := timeRow{ID: 1, Created: now.In(time.FixedZone("UTC+8", 8*3600)), SeenMs: now}
+	fm, _ := NewFieldsMap("time_table", &row)
+	fm.SetTimeLayout(layout)
+
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	args := fdb.LastQuery().args
+	if args[1] != "2024-05-06 07:08:09" {
+		t.Errorf("created bind %v", args[1])
+	}
+	if args[2] != now.UnixMilli() {
+		t.Errorf("epoch field should not use layout, bind %v", args[2])
+	}
+
+	row = timeRow{ID: 1}
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if !row.Created.Equal(now) || !row.SeenMs.Equal(now) {
+		t.Errorf("unexpected %+v", row)
+	}
+
+	fdb.handler = func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{
+			cols: []string{"id", "created", "seen_ms", "seen_s"},
+			rows: [][]driver.Value{{int64(1), "06/05/2024", nil, nil}},
+		}
+	}
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err == nil {
+		t.Error("want error for string not in layout")
+	}
+}
@@ -0,0 +1,42 @@
+package sqlmapper
+
+import (
+	"reflect"
+	"time"
+)
+
+// SetClock set time source of fields with createtime / updatetime tag option,
+// nil is time.Now (default)
+// example: fds.SetClock(func() time.Time { return fixed })
+func (fds *_FieldsMap) SetClock(now func() time.Time) {
+
+	fds.now = now
+}
+
+// fillStamps set updatetime fields, and createtime fields on insert, to now
+func (fds *_FieldsMap) fillStamps(insert bool) {
+
+	var now time.Time
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if fds.fields[i].stamp != "update" && (fds.fields[i].stamp != "create" || !insert) {
+			continue
+		}
+		if now.IsZero() {
+			now = time.Now()
+			if fds.now != nil {
+				now = fds.now()
+			}
+		}
+
+		v := reflect.ValueOf(fds.fields[i].Addr).Elem()
+		if fds.fields[i].ptr {
+			t := now
+			v.Set(reflect.ValueOf(&t))
+		} else {
+			v.Set(reflect.ValueOf(now))
+		}
+		if fds.dirty != nil {
+			fds.dirty[i] = true
+		}
+	}
+}
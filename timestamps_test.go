@@ -0,0 +1,67 @@
+package sqlmapper
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type stampedRow struct {
+	ID        int64      `sql:"id"`
+	Name      string     `sql:"name"`
+	CreatedAt time.Time  `sql:"created_at,createtime"`
+	UpdatedAt *time.Time `sql:"updated_at,updatetime"`
+}
+
+func TestTimestampFields(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	clock := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	row := stampedRow{ID: 1, Name: "ann"}
+	fm, err := NewFieldsMap("stamped_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm.SetClock(func() time.Time { return clock })
+
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if !row.CreatedAt.Equal(clock) || row.UpdatedAt == nil || !row.UpdatedAt.Equal(clock) {
+		t.Errorf("insert stamps %v %v", row.CreatedAt, row.UpdatedAt)
+	}
+	if q := fdb.LastQuery(); q.args[2] != clock || q.args[3] != clock {
+		t.Errorf("insert binds %v", q.args)
+	}
+
+	created := clock
+	clock = clock.Add(time.Hour)
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if !row.CreatedAt.Equal(created) || !row.UpdatedAt.Equal(clock) {
+		t.Errorf("update stamps %v %v", row.CreatedAt, row.UpdatedAt)
+	}
+
+	// dirty update refreshes updatetime too
+	clock = clock.Add(time.Hour)
+	fm.Set("name", "bob")
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	q := fdb.LastQuery()
+	if q.sql != "UPDATE `stamped_table` SET `name` = ?, `updated_at` = ? where `id` = ? " || q.args[1] != clock {
+		t.Errorf("dirty update %q %v", q.sql, q.args)
+	}
+
+	var bad struct {
+		ID      int64 `sql:"id"`
+		Created int64 `sql:"created,createtime"`
+	}
+	if _, err := NewFieldsMap("stamped_table", &bad); err == nil {
+		t.Error("want error for createtime on non time.Time field")
+	}
+}
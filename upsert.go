@@ -0,0 +1,116 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// SQLUpsert insert, or update on duplicate primary key / unique key.
+// updateCols are `sql` tags updated on duplicate,
+// all fields except primary key if none given.
+// example: fds.SQLUpsert(ctx, tx, db, "field_thr", "field_fou")
+// INSERT INTO `t` (...) VALUES (...) ON DUPLICATE KEY UPDATE
+// `field_thr` = VALUES(`field_thr`), `field_fou` = VALUES(`field_fou`)
+// with Postgres dialect, on conflict of primary key:
+// INSERT INTO "t" (...) VALUES (...) ON CONFLICT ("field_key") DO UPDATE SET
+// "field_thr" = EXCLUDED."field_thr", "field_fou" = EXCLUDED."field_fou"
+func (fds *_FieldsMap) SQLUpsert(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	updateCols ...string) error {
+
+	if err := fds.noVault("SQLInsert"); err != nil {
+		return err
+	}
+
+	sqlstr, err := fds.upsertSQL(updateCols)
+	if err != nil {
+		return err
+	}
+
+	err = fds.checkValues()
+	if err != nil {
+		return err
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = fds.execSQL(ctx, exec, sqlstr, fds.insertValues()...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upsertSQL generate sqlstr for upsert
+func (fds *_FieldsMap) upsertSQL(updateCols []string) (string, error) {
+
+	idxs, err := fds.upsertIndexes(updateCols)
+	if err != nil {
+		return "", err
+	}
+
+	_, pg := fds.dialect.(postgresDialect)
+
+	var sets string
+	for i, ilen := 0, len(idxs); i < ilen; i++ {
+		if len(sets) > 0 {
+			sets += ", "
+		}
+		tag := fds.quote(fds.fields[idxs[i]].Tag)
+		if pg {
+			sets += tag + " = EXCLUDED." + tag
+			continue
+		}
+		sets += tag + " = VALUES(" + tag + ")"
+	}
+
+	if pg {
+		var keys string
+		for i, plen := 0, len(fds.pks); i < plen; i++ {
+			if i > 0 {
+				keys += ", "
+			}
+			keys += fds.quote(fds.fields[fds.pks[i]].Tag)
+		}
+		return fds.insertSQL() + " ON CONFLICT (" + keys + ") DO UPDATE SET " + sets, nil
+	}
+
+	return fds.insertSQL() + " ON DUPLICATE KEY UPDATE " + sets, nil
+}
+
+// upsertIndexes index of fields updated on duplicate
+func (fds *_FieldsMap) upsertIndexes(updateCols []string) ([]int, error) {
+
+	var idxs []int
+	if len(updateCols) == 0 {
+		for i, flen := 0, len(fds.fields); i < flen; i++ {
+			if !fds.isPriKey(i) && len(fds.fields[i].expr) == 0 {
+				idxs = append(idxs, i)
+			}
+		}
+	}
+
+	for i, clen := 0, len(updateCols); i < clen; i++ {
+		idx := fds.fieldIndex(updateCols[i])
+		if idx < 0 {
+			return nil, errors.New("no field match `sql` tag:" + updateCols[i])
+		}
+		if fds.isPriKey(idx) {
+			return nil, errors.New("primary key can not be updated on duplicate:" + updateCols[i])
+		}
+		if len(fds.fields[idx].expr) > 0 {
+			return nil, errors.New("select-only field can not be updated on duplicate:" + updateCols[i])
+		}
+		idxs = append(idxs, idx)
+	}
+
+	if len(idxs) == 0 {
+		return nil, errors.New("no column to update on duplicate")
+	}
+
+	return idxs, nil
+}
@@ -0,0 +1,70 @@
+package sqlmapper
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSQLUpsert(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "key001", FieldThr: 3}
+	fm, _ := NewFieldsMap(table, &row)
+
+	err := fm.SQLUpsert(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "INSERT INTO `test_table` ( `field_key`, `field_one`, `field_two`, `field_thr`, `field_fou` ) " +
+		"VALUES (?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE `field_one` = VALUES(`field_one`), " +
+		"`field_two` = VALUES(`field_two`), `field_thr` = VALUES(`field_thr`), " +
+		"`field_fou` = VALUES(`field_fou`)"
+	if q := fdb.LastQuery(); q.sql != want || len(q.args) != 5 {
+		t.Errorf("got %q %v\nwant %q", q.sql, q.args, want)
+	}
+
+	err = fm.SQLUpsert(ctx, nil, db, "field_thr", "field_fou")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "INSERT INTO `test_table` ( `field_key`, `field_one`, `field_two`, `field_thr`, `field_fou` ) " +
+		"VALUES (?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE `field_thr` = VALUES(`field_thr`), " +
+		"`field_fou` = VALUES(`field_fou`)"
+	if q := fdb.LastQuery(); q.sql != want {
+		t.Errorf("got %q\nwant %q", q.sql, want)
+	}
+
+	if err := fm.SQLUpsert(ctx, nil, db, "created_at"); err == nil {
+		t.Error("want error for unknown column")
+	}
+	if err := fm.SQLUpsert(ctx, nil, db, "field_key"); err == nil {
+		t.Error("want error for primary key column")
+	}
+}
+
+func TestSQLUpsertPostgres(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	row := DemoRow{FieldKey: "key001", FieldThr: 3}
+	fm, _ := NewFieldsMapWithDialect(table, &row, Postgres)
+
+	// the same key twice, the second one updates
+	for i := 0; i < 2; i++ {
+		row.FieldThr++
+		if err := fm.SQLUpsert(ctx, nil, db, "field_thr"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := `INSERT INTO "test_table" ( "field_key", "field_one", "field_two", "field_thr", "field_fou" ) ` +
+		`VALUES ($1, $2, $3, $4, $5) ON CONFLICT ("field_key") DO UPDATE SET "field_thr" = EXCLUDED."field_thr"`
+	q := fdb.LastQuery()
+	if q.sql != want || len(q.args) != 5 || q.args[3] != int64(5) {
+		t.Errorf("got %q %v\nwant %q", q.sql, q.args, want)
+	}
+}
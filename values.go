@@ -0,0 +1,84 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// SQLInsertValues insert one row from values without an Object(struct),
+// values are in field order without select-only (expr) fields
+// and db assigned (`auto`) primary key,
+// each of the field's Go type or nil for NULL, the scope field is set,
+// row hash field is bound as given
+// example: fds.SQLInsertValues(ctx, tx, db,
+// 	[]interface{}{"key001", "one", true, int64(3), 1.5})
+func (fds *_FieldsMap) SQLInsertValues(ctx context.Context, tx *sql.Tx,
+	db *sql.DB, values []interface{}) error {
+
+	if err := fds.noVault("SQLInsert"); err != nil {
+		return err
+	}
+
+	binds := make([]interface{}, 0, len(values))
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if len(fds.fields[i].expr) > 0 || fds.fields[i].auto {
+			continue
+		}
+
+		n := len(binds)
+		if n >= len(values) {
+			return fmt.Errorf("got %d values, want %d", len(values),
+				len(fds.insertValues()))
+		}
+		if fds.scope != nil && fds.scope.idx == i {
+			binds = append(binds, fds.scope.value)
+			continue
+		}
+		if values[n] == nil {
+			binds = append(binds, nil)
+			continue
+		}
+
+		ft := reflect.TypeOf(fds.fields[i].Addr).Elem()
+		if reflect.TypeOf(values[n]) != ft {
+			return fmt.Errorf("value %d is %T, want %s for `%s`", n, values[n],
+				ft.String(), fds.fields[i].Tag)
+		}
+		if fds.fields[i].Type == "enum" {
+			code := reflect.ValueOf(values[n]).Int()
+			err := fds.fields[i].enum.check(code)
+			if err != nil {
+				return err
+			}
+			binds = append(binds, code)
+			continue
+		}
+		v := values[n]
+		if fds.fields[i].ptr {
+			rv := reflect.ValueOf(v)
+			if rv.IsNil() {
+				binds = append(binds, nil)
+				continue
+			}
+			v = rv.Elem().Interface()
+		}
+		binds = append(binds, fds.bindValue(i, v))
+	}
+	if len(binds) != len(values) {
+		return fmt.Errorf("got %d values, want %d", len(values), len(binds))
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = fds.execSQL(ctx, exec, fds.insertSQL(), binds...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package sqlmapper
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSQLInsertValues(t *testing.T) {
+
+	db, fdb := newFakeDB(nil)
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	err := fm.SQLInsertValues(ctx, nil, db, []interface{}{"key001", "one", true, int64(3), 1.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := fdb.LastQuery()
+	if q.sql != fm.(*_FieldsMap).insertSQL() || fmt.Sprint(q.args) != "[key001 one true 3 1.5]" {
+		t.Errorf("got %q %v", q.sql, q.args)
+	}
+
+	if err := fm.SQLInsertValues(ctx, nil, db, []interface{}{"key001", "one"}); err == nil {
+		t.Error("want error for too few values")
+	}
+	if err := fm.SQLInsertValues(ctx, nil, db,
+		[]interface{}{"key001", "one", true, int64(3), 1.5, 6}); err == nil {
+		t.Error("want error for too many values")
+	}
+	if err := fm.SQLInsertValues(ctx, nil, db,
+		[]interface{}{"key001", "one", true, 3, 1.5}); err == nil {
+		t.Error("want error for int bound to int64 field")
+	}
+
+	ms := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	tfm, _ := NewFieldsMap("time_table", &timeRow{})
+	err = tfm.SQLInsertValues(ctx, nil, db, []interface{}{int64(1), nil, ms, time.Time{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.args[1] != nil || q.args[2] != ms.UnixMilli() || q.args[3] != nil {
+		t.Errorf("time values should bind like fields, got %v", q.args)
+	}
+}
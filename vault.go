@@ -0,0 +1,89 @@
+package sqlmapper
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+)
+
+// newVaultMap FieldsMap of primary key & `vault` fields of fds,
+// on the same Object(struct), table is set by SetVault.
+// the primary key is never `auto` in the vault table: the main row's key
+// is inserted to join the vault row back
+func newVaultMap(fds *_FieldsMap, elem reflect.Value, layout *structLayout) *_FieldsMap {
+
+	fields := make([]Field, 0, len(fds.pks)+len(layout.vault))
+	pks := make([]int, 0, len(fds.pks))
+	for i, plen := 0, len(fds.pks); i < plen; i++ {
+		if fds.pks[i] < len(layout.fields) {
+			pk := newField(elem, &layout.fields[fds.pks[i]])
+			pk.auto = false
+			pks = append(pks, len(fields))
+			fields = append(fields, pk)
+		}
+	}
+	if len(pks) == 0 {
+		pks = []int{0}
+	}
+	for i, vlen := 0, len(layout.vault); i < vlen; i++ {
+		fields = append(fields, newField(elem, &layout.vault[i]))
+	}
+
+	return &_FieldsMap{
+		objptr:  fds.objptr,
+		reftype: fds.reftype,
+		fields:  fields,
+		dialect: MySQL,
+		pk:      pks[0],
+		pks:     pks,
+
+		nullPolicy: fds.nullPolicy,
+	}
+}
+
+// SetVault route fields with tag option `sql:"ssn,vault"` to table on db,
+// a row of primary key & vault fields, while the other fields stay in
+// the main table. SQLInsert, SQLSelectByPriKey, SQLUpdateByPriKey and
+// SQLDeleteByPriKey also write / read the vault row on db (never in tx),
+// they fail until SetVault is called, other methods see the main table only.
+// the two databases are not atomic: the vault row is inserted, updated
+// and read after the main row, and deleted before it.
+// example: fds.SetVault("user_secrets", vaultDB)
+func (fds *_FieldsMap) SetVault(table string, db *sql.DB) error {
+
+	if fds.vault == nil {
+		return errors.New("no field with vault option in " + fds.reftype.String())
+	}
+	if db == nil {
+		return errors.New("db is nil")
+	}
+	if err := checkTable(table); err != nil {
+		return err
+	}
+
+	fds.vault.table = table
+	fds.vault.dialect = fds.dialect
+	fds.vaultDB = db
+	return nil
+}
+
+// checkVault error if fds has vault fields but SetVault is not called
+func (fds *_FieldsMap) checkVault() error {
+
+	if fds.vault != nil && fds.vaultDB == nil {
+		return errors.New("vault fields of " + fds.table + " need SetVault")
+	}
+
+	return nil
+}
+
+// noVault error if fds has vault fields, for methods that do not write
+// the vault row, method is the one to use instead
+func (fds *_FieldsMap) noVault(method string) error {
+
+	if fds.vault != nil {
+		return errors.New("vault rows of " + fds.table + " need " + method)
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+type personRow struct {
+	ID   int64  `sql:"id"`
+	Name string `sql:"name"`
+	SSN  string `sql:"ssn,vault"`
+}
+
+func TestSetVault(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return &fakeResult{cols: []string{"id", "name"},
+				rows: [][]driver.Value{{int64(1), "ann"}}}
+		}
+		return nil
+	})
+	defer db.Close()
+	vdb, vfdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if strings.HasPrefix(q, "SELECT") {
+			return &fakeResult{cols: []string{"id", "ssn"},
+				rows: [][]driver.Value{{int64(1), "123-45-6789"}}}
+		}
+		return nil
+	})
+	defer vdb.Close()
+	ctx := context.Background()
+
+	row := personRow{ID: 1, Name: "ann", SSN: "123-45-6789"}
+	fm, err := NewFieldsMap("people", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fm.SQLInsert(ctx, nil, db); err == nil {
+		t.Fatal("want error before SetVault")
+	}
+	if err := fm.SetVault("secrets`; DROP TABLE users; --", vdb); err == nil {
+		t.Fatal("want error for bad vault table name")
+	}
+	if err := fm.SQLInsert(ctx, nil, db); err == nil || len(vfdb.Queries()) != 0 {
+		t.Fatal("bad vault table name must not be set")
+	}
+	if err := fm.SetVault("people_secrets", vdb); err != nil {
+		t.Fatal(err)
+	}
+
+	err = fm.SQLInsert(ctx, nil, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.sql != "INSERT INTO `people` ( `id`, `name` ) VALUES (?, ?)" {
+		t.Errorf("main insert got %q", q.sql)
+	}
+	if q := vfdb.LastQuery(); q.sql != "INSERT INTO `people_secrets` ( `id`, `ssn` ) VALUES (?, ?)" ||
+		q.args[1] != "123-45-6789" {
+		t.Errorf("vault insert got %q %v", q.sql, q.args)
+	}
+
+	row = personRow{ID: 1}
+	if _, err := fm.SQLSelectByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if row.Name != "ann" || row.SSN != "123-45-6789" {
+		t.Errorf("select got %+v", row)
+	}
+
+	row.SSN = "987-65-4321"
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := vfdb.LastQuery(); !strings.HasPrefix(q.sql, "UPDATE `people_secrets` SET  `id` = ?, `ssn` = ?") ||
+		q.args[1] != "987-65-4321" {
+		t.Errorf("vault update got %q %v", q.sql, q.args)
+	}
+
+	n := len(vfdb.Queries())
+	if err := fm.SQLDeleteByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if len(vfdb.Queries()) != n+1 || !strings.HasPrefix(fdb.LastQuery().sql, "DELETE FROM `people`") {
+		t.Error("delete should remove vault and main rows")
+	}
+
+	var demo DemoRow
+	dfm, _ := NewFieldsMap(table, &demo)
+	if err := dfm.SetVault("secrets", vdb); err == nil {
+		t.Error("want error without vault fields")
+	}
+}
+
+func TestVaultAutoKey(t *testing.T) {
+
+	type autoPerson struct {
+		ID   int64  `sql:"id,pk,auto"`
+		Name string `sql:"name"`
+		SSN  string `sql:"ssn,vault"`
+	}
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{affected: 1, lastID: 42}
+	})
+	defer db.Close()
+	vdb, vfdb := newFakeDB(nil)
+	defer vdb.Close()
+	ctx := context.Background()
+
+	row := autoPerson{Name: "ann", SSN: "123-45-6789"}
+	fm, err := NewFieldsMap("people", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fm.SetVault("people_secrets", vdb); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fm.SQLInsert(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if q := fdb.LastQuery(); q.sql != "INSERT INTO `people` ( `name` ) VALUES (?)" {
+		t.Errorf("main insert got %q", q.sql)
+	}
+	if q := vfdb.LastQuery(); q.sql != "INSERT INTO `people_secrets` ( `id`, `ssn` ) VALUES (?, ?)" ||
+		q.args[0] != int64(42) || q.args[1] != "123-45-6789" {
+		t.Errorf("vault insert got %q %v", q.sql, q.args)
+	}
+
+	n := len(fdb.Queries())
+	other := autoPerson{Name: "bob"}
+	if err := fm.SQLInsertBatch(ctx, nil, db, []interface{}{&row, &other}); err == nil {
+		t.Error("SQLInsertBatch: want error with vault fields")
+	}
+	if err := fm.SQLUpsert(ctx, nil, db); err == nil {
+		t.Error("SQLUpsert: want error with vault fields")
+	}
+	if err := fm.SQLInsertValues(ctx, nil, db, []interface{}{"bob", "000"}); err == nil {
+		t.Error("SQLInsertValues: want error with vault fields")
+	}
+	if err := fm.SQLUpdateByCond(ctx, nil, db, " where `name` = ? ", "ann"); err == nil {
+		t.Error("SQLUpdateByCond: want error with vault fields")
+	}
+	if len(fdb.Queries()) != n {
+		t.Error("no query should run for methods not writing the vault row")
+	}
+}
+
+func TestGenericVault(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return &fakeResult{cols: []string{"id", "name"},
+			rows: [][]driver.Value{{int64(1), "ann"}}}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	// generic funcs & Mapper can not write or read the vault row
+	if _, err := NewMapper[personRow]("people"); err == nil {
+		t.Error("NewMapper: want error for vault fields")
+	}
+	if _, err := SelectByPriKey[personRow](ctx, db, "people", int64(1)); err == nil {
+		t.Error("SelectByPriKey: want error for vault fields")
+	}
+	if _, err := SelectAll[personRow](ctx, db, "people", ""); err == nil {
+		t.Error("SelectAll: want error for vault fields")
+	}
+	var rows [1]personRow
+	if _, err := SelectInto(ctx, db, "people", rows[:], ""); err == nil {
+		t.Error("SelectInto: want error for vault fields")
+	}
+	rowCh, errCh := SelectChan[personRow](ctx, db, "people", "")
+	for range rowCh {
+		t.Error("SelectChan: want no row for vault fields")
+	}
+	if err := <-errCh; err == nil {
+		t.Error("SelectChan: want error for vault fields")
+	}
+
+	// Mapper built without NewMapper still checks on each call
+	m := &Mapper[personRow]{table: "people"}
+	if err := m.Insert(ctx, nil, db, &personRow{ID: 1}); err == nil {
+		t.Error("Insert: want error for vault fields")
+	}
+	if err := m.Delete(ctx, nil, db, &personRow{ID: 1}); err == nil {
+		t.Error("Delete: want error for vault fields")
+	}
+
+	if n := len(fdb.Queries()); n != 0 {
+		t.Errorf("got %d queries, want none", n)
+	}
+}
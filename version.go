@@ -0,0 +1,70 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+)
+
+// versionIndex index of field with `version` tag option, -1 if none
+func (fds *_FieldsMap) versionIndex() int {
+
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if fds.fields[i].version {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// updateVersioned UPDATE by primary key and current version of field vidx,
+// which is increased by 1 in db and in Object(struct) on success,
+// only dirty fields (and row hash field) are set if any field is Set,
+// ErrVersionConflict if no row matched
+// UPDATE `t` SET `a` = ?, `version` = `version` + 1 where `id` = ? AND `version` = ?
+func (fds *_FieldsMap) updateVersioned(ctx context.Context, exec Executor,
+	vidx int) (sql.Result, error) {
+
+	var sets string
+	var values []interface{}
+	for i, flen := 0, len(fds.fields); i < flen; i++ {
+		if i == vidx || len(fds.fields[i].expr) > 0 {
+			continue
+		}
+		if fds.dirty != nil && !fds.dirty[i] && !fds.fields[i].hash {
+			continue
+		}
+		sets += fds.quote(fds.fields[i].Tag) + " = ?, "
+		values = append(values, fds.GetFieldValue(i))
+	}
+	ver := fds.quote(fds.fields[vidx].Tag)
+	sets += ver + " = " + ver + " + 1"
+
+	condArgs := append(fds.priKeyValues(), fds.GetFieldValue(vidx))
+	extStr, args := fds.scoped(" where "+fds.priKeyCond()+" AND "+ver+" = ? ", condArgs...)
+	values = append(values, args...)
+	sqlstr := fds.verb("UPDATE") + fds.quoteTable() + " SET " + sets + extStr
+	res, err := fds.execSQL(ctx, exec, sqlstr, values...)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrVersionConflict
+	}
+
+	v := reflect.ValueOf(fds.fields[vidx].Addr).Elem()
+	if v.CanUint() {
+		v.SetUint(v.Uint() + 1)
+	} else {
+		v.SetInt(v.Int() + 1)
+	}
+	fds.ClearDirty()
+
+	return res, nil
+}
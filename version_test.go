@@ -0,0 +1,65 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+type versionedRow struct {
+	ID      int64  `sql:"id"`
+	Name    string `sql:"name"`
+	Version int32  `sql:"version,version"`
+}
+
+func TestVersionConflict(t *testing.T) {
+
+	current := int64(3)
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		if args[len(args)-1] != current {
+			return &fakeResult{affected: 0}
+		}
+		current++
+		return &fakeResult{affected: 1}
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	row := versionedRow{ID: 1, Name: "ann", Version: 3}
+	fm, err := NewFieldsMap("versioned_table", &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	q := fdb.LastQuery()
+	want := "UPDATE `versioned_table` SET `id` = ?, `name` = ?, `version` = `version` + 1 where `id` = ? AND `version` = ? "
+	if q.sql != want || len(q.args) != 4 {
+		t.Errorf("got %q %v\nwant %q", q.sql, q.args, want)
+	}
+	if row.Version != 4 {
+		t.Errorf("version not increased: %d", row.Version)
+	}
+
+	// another writer bumps the version, this copy is stale
+	current = 7
+	row.Name = "bob"
+	err = fm.SQLUpdateByPriKey(ctx, nil, db)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("want ErrVersionConflict, got %v", err)
+	}
+	if row.Version != 4 {
+		t.Errorf("version changed on conflict: %d", row.Version)
+	}
+
+	row.Version = 7
+	if err := fm.SQLUpdateByPriKey(ctx, nil, db); err != nil {
+		t.Fatal(err)
+	}
+	if row.Version != 8 {
+		t.Errorf("version not increased: %d", row.Version)
+	}
+}
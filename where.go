@@ -0,0 +1,176 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Condition WHERE condition on columns by `sql` tag, built by
+// Eq, Gt, Lt, In, Like, Between, And & Or, values are always bound,
+// columns are checked against fields when used by a FieldsMap
+// example: sqlmapper.And(sqlmapper.Eq("field_one", "one"),
+// 	sqlmapper.Or(sqlmapper.Gt("field_thr", 3), sqlmapper.In("field_key", keys)))
+type Condition struct {
+	op   string
+	col  string
+	args []interface{}
+	subs []*Condition
+}
+
+// Eq column = value, IS NULL for nil value
+func Eq(nameInDB string, value interface{}) *Condition {
+
+	return &Condition{op: "=", col: nameInDB, args: []interface{}{value}}
+}
+
+// Gt column > value
+func Gt(nameInDB string, value interface{}) *Condition {
+
+	return &Condition{op: ">", col: nameInDB, args: []interface{}{value}}
+}
+
+// Lt column < value
+func Lt(nameInDB string, value interface{}) *Condition {
+
+	return &Condition{op: "<", col: nameInDB, args: []interface{}{value}}
+}
+
+// In column IN values, values is a slice, matches nothing if empty
+func In(nameInDB string, values interface{}) *Condition {
+
+	return &Condition{op: "IN", col: nameInDB, args: []interface{}{values}}
+}
+
+// Like column LIKE pattern, pattern is bound as is
+func Like(nameInDB string, pattern string) *Condition {
+
+	return &Condition{op: "LIKE", col: nameInDB, args: []interface{}{pattern}}
+}
+
+// Between column BETWEEN low AND high, both bounds included
+func Between(nameInDB string, low, high interface{}) *Condition {
+
+	return &Condition{op: "BETWEEN", col: nameInDB, args: []interface{}{low, high}}
+}
+
+// And all of conds
+func And(conds ...*Condition) *Condition {
+
+	return &Condition{op: "AND", subs: conds}
+}
+
+// Or any of conds
+func Or(conds ...*Condition) *Condition {
+
+	return &Condition{op: "OR", subs: conds}
+}
+
+// Where " where ..." extStr and its args of cond,
+// error if a column matches no `sql` tag
+// example: extStr, args, err := fds.Where(sqlmapper.Eq("field_one", "one"))
+// extStr is " where `field_one` = ? ", args is ["one"]
+func (fds *_FieldsMap) Where(cond *Condition) (string, []interface{}, error) {
+
+	str, args, err := fds.condStr(cond)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return " where " + str + " ", args, nil
+}
+
+// condStr SQL of cond and its args
+func (fds *_FieldsMap) condStr(cond *Condition) (string, []interface{}, error) {
+
+	if cond == nil {
+		return "", nil, errors.New("nil condition")
+	}
+
+	if cond.op == "AND" || cond.op == "OR" {
+		if len(cond.subs) == 0 {
+			return "", nil, errors.New("empty " + cond.op + " condition")
+		}
+		parts := make([]string, len(cond.subs))
+		var args []interface{}
+		for i, slen := 0, len(cond.subs); i < slen; i++ {
+			str, subArgs, err := fds.condStr(cond.subs[i])
+			if err != nil {
+				return "", nil, err
+			}
+			parts[i] = str
+			args = append(args, subArgs...)
+		}
+		return "(" + strings.Join(parts, " "+cond.op+" ") + ")", args, nil
+	}
+
+	idx := fds.fieldIndex(cond.col)
+	if idx < 0 {
+		return "", nil, errors.New("no field match `sql` tag:" + cond.col)
+	}
+	col := fds.quote(fds.fields[idx].Tag)
+
+	switch cond.op {
+	case "=":
+		if isNullValue(cond.args[0]) {
+			return col + " IS NULL", nil, nil
+		}
+	case "IN":
+		rv := reflect.ValueOf(cond.args[0])
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return "", nil, errors.New("IN values of non slice:" + cond.col)
+		}
+		if rv.Len() == 0 {
+			return "1 = 0", nil, nil
+		}
+		args := make([]interface{}, rv.Len())
+		for i, vlen := 0, rv.Len(); i < vlen; i++ {
+			args[i] = rv.Index(i).Interface()
+		}
+		return col + " IN (" + placeholders(len(args)) + ")", args, nil
+	case "BETWEEN":
+		return col + " BETWEEN ? AND ?", cond.args, nil
+	default:
+	}
+
+	return col + " " + cond.op + " ?", cond.args, nil
+}
+
+// SQLSelectWhere select rows matching cond
+// example: fds.SQLSelectWhere(ctx, tx, db, sqlmapper.In("field_key", keys))
+func (fds *_FieldsMap) SQLSelectWhere(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	cond *Condition) ([]interface{}, error) {
+
+	whereStr, whereArgs, err := fds.Where(cond)
+	if err != nil {
+		return nil, err
+	}
+
+	exec, err := getExecutor(tx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	extStr, args := fds.scoped(whereStr, whereArgs...)
+	return fds.selectRows(ctx, exec, scanByPosition, fds.selectSQL(extStr), args...)
+}
+
+// SQLSelectByLike select rows of column nameInDB LIKE pattern,
+// pattern is bound as is, % and _ are up to the caller
+// example: fds.SQLSelectByLike(ctx, tx, db, "field_one", "%foo%")
+func (fds *_FieldsMap) SQLSelectByLike(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	nameInDB string, pattern string) ([]interface{}, error) {
+
+	return fds.SQLSelectWhere(ctx, tx, db, Like(nameInDB, pattern))
+}
+
+// SQLSelectBetween select rows of column nameInDB BETWEEN low AND high,
+// both bounds included
+// example: fds.SQLSelectBetween(ctx, tx, db, "created_at", from, to)
+func (fds *_FieldsMap) SQLSelectBetween(ctx context.Context, tx *sql.Tx, db *sql.DB,
+	nameInDB string, low, high interface{}) ([]interface{}, error) {
+
+	return fds.SQLSelectWhere(ctx, tx, db, Between(nameInDB, low, high))
+}
@@ -0,0 +1,137 @@
+package sqlmapper
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestSQLSelectWhere(t *testing.T) {
+
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		return demoRowsResult(2)
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	cond := And(
+		Eq("field_one", "one"),
+		Or(Gt("field_thr", 3), Lt("field_fou", 1.5), Eq("field_two", nil)),
+		In("field_key", []string{"keya", "keyb"}),
+		Like("field_one", "on%"),
+	)
+	objs, err := fm.SQLSelectWhere(ctx, nil, db, cond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 {
+		t.Errorf("unexpected rows %v", objs)
+	}
+	q := fdb.LastQuery()
+	want := "SELECT  `field_key`, `field_one`, `field_two`, `field_thr`, `field_fou`  FROM `test_table`  where " +
+		"(`field_one` = ? AND (`field_thr` > ? OR `field_fou` < ? OR `field_two` IS NULL) AND " +
+		"`field_key` IN (?, ?) AND `field_one` LIKE ?) "
+	if q.sql != want {
+		t.Errorf("got %q\nwant %q", q.sql, want)
+	}
+	if len(q.args) != 6 || q.args[0] != "one" || q.args[1] != int64(3) || q.args[3] != "keya" ||
+		q.args[4] != "keyb" || q.args[5] != "on%" {
+		t.Errorf("unexpected args %v", q.args)
+	}
+
+	if s, args, _ := fm.Where(In("field_key", []string{})); s != " where 1 = 0 " || len(args) != 0 {
+		t.Errorf("empty IN got %q %v", s, args)
+	}
+	if _, _, err := fm.Where(Eq("field_one; DROP TABLE t", 1)); err == nil {
+		t.Error("want error for unknown column")
+	}
+	if _, _, err := fm.Where(In("field_key", "keya")); err == nil {
+		t.Error("want error for IN of non slice")
+	}
+	if _, _, err := fm.Where(Or()); err == nil {
+		t.Error("want error for empty OR")
+	}
+}
+
+func TestSQLSelectByLike(t *testing.T) {
+
+	names := []string{"foo", "barfoo", "bar", "food", "baz"}
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		res := demoRowsResult(len(names))
+		var rows [][]driver.Value
+		for i, r := range res.rows {
+			// the fake driver only knows %foo%
+			if args[0] == "%foo%" && strings.Contains(names[i], "foo") {
+				r[1] = names[i]
+				rows = append(rows, r)
+			}
+		}
+		res.rows = rows
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	objs, err := fm.SQLSelectByLike(ctx, nil, db, "field_one", "%foo%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, obj := range objs {
+		got = append(got, obj.(*DemoRow).FieldOne)
+	}
+	if strings.Join(got, ",") != "foo,barfoo,food" {
+		t.Errorf("got %v", got)
+	}
+	if q := fdb.LastQuery(); !strings.HasSuffix(q.sql, " where `field_one` LIKE ? ") || q.args[0] != "%foo%" {
+		t.Errorf("unexpected %q %v", q.sql, q.args)
+	}
+
+	if _, err := fm.SQLSelectByLike(ctx, nil, db, "no_field", "%foo%"); err == nil {
+		t.Error("want error for unknown column")
+	}
+}
+
+func TestSQLSelectBetween(t *testing.T) {
+
+	all := demoRowsResult(6)
+	db, fdb := newFakeDB(func(q string, args []driver.Value) *fakeResult {
+		res := &fakeResult{cols: all.cols}
+		for _, r := range all.rows {
+			if v := r[3].(int64); v >= args[0].(int64) && v <= args[1].(int64) {
+				res.rows = append(res.rows, r)
+			}
+		}
+		return res
+	})
+	defer db.Close()
+	ctx := context.Background()
+
+	var row DemoRow
+	fm, _ := NewFieldsMap(table, &row)
+
+	objs, err := fm.SQLSelectBetween(ctx, nil, db, "field_thr", 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 3 || objs[0].(*DemoRow).FieldThr != 2 || objs[2].(*DemoRow).FieldThr != 4 {
+		t.Errorf("range should include both bounds, got %v", objs)
+	}
+	q := fdb.LastQuery()
+	if !strings.HasSuffix(q.sql, " where `field_thr` BETWEEN ? AND ? ") ||
+		len(q.args) != 2 || q.args[0] != int64(2) || q.args[1] != int64(4) {
+		t.Errorf("unexpected %q %v", q.sql, q.args)
+	}
+
+	_, err = fm.SQLSelectBetween(ctx, nil, db, "field_six", 2, 4)
+	if err == nil || !strings.Contains(err.Error(), "field_six") {
+		t.Errorf("want error naming unknown column, got %v", err)
+	}
+}